@@ -3,22 +3,98 @@ package aa
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/xackery/critsprinkler/tracker"
+	"github.com/xackery/critsprinkler/util"
 )
 
+// minRateDuration keeps per hour rates from spiking right after the parse or a zone starts
+const minRateDuration = time.Minute
+
+// ExpKind is who an experience gain was shared with
+type ExpKind int
+
+const (
+	ExpKindSolo ExpKind = iota
+	ExpKindParty
+	ExpKindRaid
+	ExpKindMax
+)
+
+func (e ExpKind) String() string {
+	switch e {
+	case ExpKindSolo:
+		return "solo"
+	case ExpKindParty:
+		return "party"
+	case ExpKindRaid:
+		return "raid"
+	}
+	return "unknown"
+}
+
+// Track is something experience fills up: a level, or an alternate advancement point
+type Track int
+
+const (
+	TrackLevel Track = iota
+	TrackAA
+	TrackMax
+)
+
+func (t Track) String() string {
+	switch t {
+	case TrackLevel:
+		return "level"
+	case TrackAA:
+		return "AA"
+	}
+	return "unknown"
+}
+
+// Gain is the experience, level or AA point a log line gave
+type Gain struct {
+	IsExp   bool    // an experience message
+	Kind    ExpKind // who the experience was shared with
+	Percent float64 // percent of the track gained, 0 when the message doesn't say
+	Track   Track   // what the percent went to
+	Dings   int     // levels or AA points gained
+}
+
+// ZoneStats is the experience gained in a zone, durations and rates use log time
+type ZoneStats struct {
+	Zone     string
+	Duration time.Duration
+	Gains    [ExpKindMax]int
+	Percent  [TrackMax]float64
+	Dings    [TrackMax]int
+}
+
+// progress is how far along a track is during the parse
+type progress struct {
+	dings   []time.Time // log time of every ding
+	percent float64     // percent gained since the last ding
+}
+
 type AA struct {
-	lastZoneEvent time.Time
-	lastAAExpDing time.Time
-	parseStart    time.Time
-	totalAAGained int
-	zone          string
+	start   time.Time // log time of the first live line, nothing before it counts
+	zone    string
+	entered time.Time // log time the current zone was entered
+	zones   map[string]*ZoneStats
+	order   []string
+	tracks  [TrackMax]progress
 }
 
 var (
-	instance    *AA
-	aaGainRegex = regexp.MustCompile(`You have gained an ability point!`)
+	instance *AA
+	expRegex = regexp.MustCompile(`\] You gain(?:ed)? (?:(?P<kind>party|raid) )?experience[!.]*(?: ?\((?P<percent>[\d.]+)%(?P<aa> AA| of an AA| of an ability point)?\)[!.]*)?$`)
+	// percent style messages from clients that split the slider, e.g. You gained 1.234% of your AA experience!
+	expPercentRegex = regexp.MustCompile(`\] You gain(?:ed)? (?P<percent>[\d.]+)% (?:of your )?(?:(?P<kind>party|raid) )?(?P<aa>AA |alternate advancement )?experience`)
+	levelRegex      = regexp.MustCompile(`\] You have gained (?:a|(?P<count>\d+)) levels?!`)
+	aaGainRegex     = regexp.MustCompile(`\] You have gained (?:an|(?P<count>\d+)) ability points?!`)
 )
 
 func New() (*AA, error) {
@@ -26,18 +102,9 @@ func New() (*AA, error) {
 		return nil, fmt.Errorf("aa already exists")
 	}
 	a := &AA{
-		zone:          "Unknown",
-		lastZoneEvent: time.Now(),
-		parseStart:    time.Now(),
-	}
-
-	/* w, err := os.Create("aa.txt")
-	if err != nil {
-		return nil, fmt.Errorf("aa create file: %w", err)
+		zone:  "Unknown",
+		zones: map[string]*ZoneStats{},
 	}
-	defer w.Close()
-
-	w.WriteString("") */
 
 	err := tracker.Subscribe(a.onLine)
 	if err != nil {
@@ -51,42 +118,277 @@ func New() (*AA, error) {
 	return a, nil
 }
 
+// Current returns the experience tracker, or nil before New
+func Current() *AA {
+	return instance
+}
+
 func (a *AA) onLine(event time.Time, line string) {
-	a.onAA(event, line)
+	// lines replayed from before the overlay started would skew the rates
+	if !tracker.IsLiveParse() {
+		return
+	}
+	gain, ok := Parse(line)
+	if !ok {
+		return
+	}
+	a.add(event, gain)
 }
 
 func (a *AA) onZone(event time.Time, zoneName string) {
-	a.lastZoneEvent = time.Now()
+	if !a.start.IsZero() {
+		a.stats(a.zone).Duration += event.Sub(a.since())
+	}
 	a.zone = zoneName
-	a.totalAAGained = 0
+	a.entered = event
 }
 
-func (a *AA) onAA(event time.Time, line string) {
-	match := aaGainRegex.FindStringSubmatch(line)
-	if len(match) < 1 {
-		return
+// add counts a gain that happened at event in the current zone
+func (a *AA) add(event time.Time, gain Gain) {
+	if a.start.IsZero() {
+		a.start = event
+	}
+	z := a.stats(a.zone)
+	if gain.IsExp {
+		z.Gains[gain.Kind]++
+	}
+	progress := &a.tracks[gain.Track]
+	z.Percent[gain.Track] += gain.Percent
+	progress.percent += gain.Percent
+	if gain.Dings > 0 {
+		z.Dings[gain.Track] += gain.Dings
+		progress.dings = append(progress.dings, event)
+		progress.percent = 0
 	}
+}
 
-	event = time.Now()
-	sinceLastDing := event.Sub(a.lastAAExpDing).Minutes()
-	a.lastAAExpDing = event
-	a.totalAAGained++
+// since returns when the current zone started counting
+func (a *AA) since() time.Time {
+	if a.entered.After(a.start) {
+		return a.entered
+	}
+	return a.start
+}
 
-	elapsedTime := event.Sub(a.lastZoneEvent).Hours()
-	if elapsedTime <= 0 {
-		fmt.Println("Last zone event was", elapsedTime, "hours ago", a.lastZoneEvent, event)
-		fmt.Println("Elapsed time is 0")
-		return
+func (a *AA) stats(zone string) *ZoneStats {
+	z, ok := a.zones[zone]
+	if !ok {
+		z = &ZoneStats{Zone: zone}
+		a.zones[zone] = z
+		a.order = append(a.order, zone)
 	}
-	aaPerHour := float64(a.totalAAGained) / elapsedTime
-	fmt.Printf("Total AA gained: %d / per hour: %.2f, Time in zone: %0.2f hours, last AA ding: %0.2f minutes\n", a.totalAAGained, aaPerHour, elapsedTime, sinceLastDing)
+	return z
+}
 
-	/* 	w, err := os.Create("aa.txt")
-	   	if err != nil {
-	   		fmt.Println(err)
-	   		return
-	   	}
-	   	defer w.Close()
+// Zones returns the experience gained in each zone until now, in the order they were first visited
+func (a *AA) Zones(now time.Time) []ZoneStats {
+	out := []ZoneStats{}
+	for _, zone := range a.order {
+		z := *a.zones[zone]
+		if zone == a.zone && now.After(a.since()) {
+			z.Duration += now.Sub(a.since())
+		}
+		out = append(out, z)
+	}
+	return out
+}
+
+// Total returns the experience gained in every zone until now
+func (a *AA) Total(now time.Time) ZoneStats {
+	total := ZoneStats{Zone: "Total"}
+	if !a.start.IsZero() && now.After(a.start) {
+		total.Duration = now.Sub(a.start)
+	}
+	for _, z := range a.Zones(now) {
+		for kind := range ExpKindMax {
+			total.Gains[kind] += z.Gains[kind]
+		}
+		for track := range TrackMax {
+			total.Percent[track] += z.Percent[track]
+			total.Dings[track] += z.Dings[track]
+		}
+	}
+	return total
+}
 
-	   	w.WriteString(fmt.Sprintf("%d AA per hour in %0.2f hours\n", int(aaPerHour), elapsedTime)) */
+// CurrentZone returns the experience gained in the zone the player is in
+func (a *AA) CurrentZone(now time.Time) ZoneStats {
+	for _, z := range a.Zones(now) {
+		if z.Zone == a.zone {
+			return z
+		}
+	}
+	return ZoneStats{Zone: a.zone}
+}
+
+// TimeToNext estimates how long until the next level or AA point. Messages with percents give the rate
+// and a ding tells where the bar is, without percents the time between dings is used. ok is false until
+// there is enough to go on
+func (a *AA) TimeToNext(track Track, now time.Time) (time.Duration, bool) {
+	progress := a.tracks[track]
+	if len(progress.dings) == 0 {
+		return 0, false
+	}
+	last := progress.dings[len(progress.dings)-1]
+
+	total := a.Total(now)
+	rate := total.PercentPerHour(track)
+	if rate > 0 {
+		remaining := 100 - progress.percent
+		if remaining < 0 {
+			remaining = 0
+		}
+		return hours(remaining / rate), true
+	}
+
+	if len(progress.dings) < 2 {
+		return 0, false
+	}
+	between := last.Sub(progress.dings[0]) / time.Duration(len(progress.dings)-1)
+	remaining := between - now.Sub(last)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// GainCount returns the experience messages of every kind
+func (z ZoneStats) GainCount() int {
+	total := 0
+	for _, gains := range z.Gains {
+		total += gains
+	}
+	return total
+}
+
+// GainsPerHour returns experience messages per hour of every kind
+func (z ZoneStats) GainsPerHour() float64 {
+	return perHour(float64(z.GainCount()), z.Duration)
+}
+
+// PercentPerHour returns the percent of a level or AA gained per hour, 0 if no message had a percent
+func (z ZoneStats) PercentPerHour(track Track) float64 {
+	return perHour(z.Percent[track], z.Duration)
+}
+
+// DingsPerHour returns the levels or AA points gained per hour
+func (z ZoneStats) DingsPerHour(track Track) float64 {
+	return perHour(float64(z.Dings[track]), z.Duration)
+}
+
+func perHour(amount float64, elapsed time.Duration) float64 {
+	if elapsed < minRateDuration {
+		return 0
+	}
+	return amount / elapsed.Hours()
+}
+
+func hours(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour))
+}
+
+// Parse returns the experience, level or AA point a log line gave, if any
+func Parse(line string) (Gain, bool) {
+	line = strings.TrimRight(line, "\r ")
+
+	match := expRegex.FindStringSubmatch(line)
+	regex := expRegex
+	if match == nil {
+		match = expPercentRegex.FindStringSubmatch(line)
+		regex = expPercentRegex
+	}
+	if match != nil {
+		gain := Gain{IsExp: true}
+		switch match[regex.SubexpIndex("kind")] {
+		case "party":
+			gain.Kind = ExpKindParty
+		case "raid":
+			gain.Kind = ExpKindRaid
+		}
+		percent := match[regex.SubexpIndex("percent")]
+		if percent != "" {
+			val, err := strconv.ParseFloat(percent, 64)
+			if err == nil {
+				gain.Percent = val
+			}
+		}
+		if match[regex.SubexpIndex("aa")] != "" {
+			gain.Track = TrackAA
+		}
+		return gain, true
+	}
+
+	for _, ding := range []struct {
+		regex *regexp.Regexp
+		track Track
+	}{
+		{levelRegex, TrackLevel},
+		{aaGainRegex, TrackAA},
+	} {
+		match = ding.regex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		gain := Gain{Track: ding.track, Dings: 1}
+		count := match[ding.regex.SubexpIndex("count")]
+		if count != "" {
+			val, err := strconv.Atoi(count)
+			if err != nil || val <= 0 {
+				return Gain{}, false
+			}
+			gain.Dings = val
+		}
+		return gain, true
+	}
+	return Gain{}, false
+}
+
+// Report summarizes experience for the whole parse, followed by each zone
+func Report(now time.Time) string {
+	if instance == nil || instance.start.IsZero() {
+		return "No experience gained yet."
+	}
+	total := instance.Total(now)
+	lines := []string{fmt.Sprintf("This session: %s", statsLine(total))}
+	for track := range TrackMax {
+		lines = append(lines, fmt.Sprintf("Next %s: %s", track, instance.NextText(track, now)))
+	}
+	lines = append(lines, "")
+	for _, z := range instance.Zones(now) {
+		lines = append(lines, fmt.Sprintf("%s: %s", z.Zone, statsLine(z)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NextText returns the estimated time to the next level or AA point, or ? if there is no estimate yet
+func (a *AA) NextText(track Track, now time.Time) string {
+	d, ok := a.TimeToNext(track, now)
+	if !ok {
+		return "?"
+	}
+	return util.FormatDuration(d)
+}
+
+// statsLine describes gains and rates, e.g. 12 gains in 1h05m, 11.1/hr (3 party), AA dings 2 (1.8/hr)
+func statsLine(z ZoneStats) string {
+	kinds := []string{}
+	for kind := range ExpKindMax {
+		if z.Gains[kind] == 0 {
+			continue
+		}
+		kinds = append(kinds, fmt.Sprintf("%d %s", z.Gains[kind], kind))
+	}
+	line := fmt.Sprintf("%d gains in %s, %.1f/hr", z.GainCount(), util.FormatDuration(z.Duration), z.GainsPerHour())
+	if len(kinds) > 0 {
+		line += " (" + strings.Join(kinds, ", ") + ")"
+	}
+	for track := range TrackMax {
+		if z.Percent[track] > 0 {
+			line += fmt.Sprintf(", %.1f%% %s/hr", z.PercentPerHour(track), track)
+		}
+		if z.Dings[track] > 0 {
+			line += fmt.Sprintf(", %s dings %d (%.1f/hr)", track, z.Dings[track], z.DingsPerHour(track))
+		}
+	}
+	return line
 }
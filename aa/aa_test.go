@@ -0,0 +1,71 @@
+package aa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Gain
+	}{
+		{"[Mon Jan 02 15:04:05 2026] You gain experience!!", Gain{IsExp: true}},
+		{"[Mon Jan 02 15:04:05 2026] You gain party experience!!\r", Gain{IsExp: true, Kind: ExpKindParty}},
+		{"[Mon Jan 02 15:04:05 2026] You gained raid experience!", Gain{IsExp: true, Kind: ExpKindRaid}},
+		{"[Mon Jan 02 15:04:05 2026] You gain experience! (1.250%)", Gain{IsExp: true, Percent: 1.25}},
+		{"[Mon Jan 02 15:04:05 2026] You gain party experience (0.5% AA)!", Gain{IsExp: true, Kind: ExpKindParty, Percent: 0.5, Track: TrackAA}},
+		{"[Mon Jan 02 15:04:05 2026] You gained 2.5% of your AA experience!", Gain{IsExp: true, Percent: 2.5, Track: TrackAA}},
+		{"[Mon Jan 02 15:04:05 2026] You have gained a level! Welcome to level 61!", Gain{Track: TrackLevel, Dings: 1}},
+		{"[Mon Jan 02 15:04:05 2026] You have gained an ability point!  You now have 5 ability points.", Gain{Track: TrackAA, Dings: 1}},
+		{"[Mon Jan 02 15:04:05 2026] You have gained 3 ability points!", Gain{Track: TrackAA, Dings: 3}},
+	}
+	for _, test := range tests {
+		got, ok := Parse(test.line)
+		if !ok || got != test.want {
+			t.Errorf("%s: got %+v %t, want %+v", test.line, got, ok, test.want)
+		}
+	}
+	_, ok := Parse("[Mon Jan 02 15:04:05 2026] Soandso tells you, 'You gain experience!!'")
+	if ok {
+		t.Errorf("tell: expected no gain")
+	}
+}
+
+func TestZones(t *testing.T) {
+	start := time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC)
+	a := &AA{zone: "Crystal Caverns", zones: map[string]*ZoneStats{}}
+	a.onZone(start.Add(-time.Hour), "Crystal Caverns")
+	a.add(start, Gain{IsExp: true, Percent: 10, Track: TrackAA})
+	a.add(start.Add(10*time.Minute), Gain{Track: TrackAA, Dings: 1})
+	a.add(start.Add(20*time.Minute), Gain{IsExp: true, Kind: ExpKindParty, Percent: 20, Track: TrackAA})
+	a.onZone(start.Add(30*time.Minute), "Cobalt Scar")
+	a.add(start.Add(40*time.Minute), Gain{IsExp: true, Kind: ExpKindRaid, Percent: 30, Track: TrackAA})
+
+	now := start.Add(time.Hour)
+	zones := a.Zones(now)
+	if len(zones) != 2 {
+		t.Fatalf("zones: got %+v", zones)
+	}
+	// the zone was entered before the first live line, so it only counts from there
+	if zones[0].Duration != 30*time.Minute || zones[0].GainsPerHour() != 4 || zones[0].PercentPerHour(TrackAA) != 60 {
+		t.Errorf("zones[0]: got %+v", zones[0])
+	}
+	if zones[1].Duration != 30*time.Minute || zones[1].Gains[ExpKindRaid] != 1 {
+		t.Errorf("zones[1]: got %+v", zones[1])
+	}
+
+	total := a.Total(now)
+	if total.Duration != time.Hour || total.PercentPerHour(TrackAA) != 60 || total.Dings[TrackAA] != 1 {
+		t.Errorf("total: got %+v", total)
+	}
+	// 50% since the ding at 60% an hour leaves 50 minutes
+	got, ok := a.TimeToNext(TrackAA, now)
+	if !ok || got != 50*time.Minute {
+		t.Errorf("time to next AA: got %s %t, want 50m", got, ok)
+	}
+	_, ok = a.TimeToNext(TrackLevel, now)
+	if ok {
+		t.Errorf("time to next level: expected no estimate without a ding")
+	}
+}
@@ -0,0 +1,156 @@
+package common
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/xackery/critsprinkler/util"
+)
+
+// Anchor is what a placement's position is kept relative to when the overlay changes size
+type Anchor int
+
+const (
+	// AnchorScaled scales position and size with the overlay
+	AnchorScaled Anchor = iota
+	AnchorTopLeft
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorCenter
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+	AnchorMax
+)
+
+func (e Anchor) String() string {
+	switch e {
+	case AnchorScaled:
+		return "Scaled"
+	case AnchorTopLeft:
+		return "Top Left"
+	case AnchorTop:
+		return "Top"
+	case AnchorTopRight:
+		return "Top Right"
+	case AnchorLeft:
+		return "Left"
+	case AnchorCenter:
+		return "Center"
+	case AnchorRight:
+		return "Right"
+	case AnchorBottomLeft:
+		return "Bottom Left"
+	case AnchorBottom:
+		return "Bottom"
+	case AnchorBottomRight:
+		return "Bottom Right"
+	}
+	return "unknown"
+}
+
+// IsValid returns true for scaled or one of the nine anchor points
+func (e Anchor) IsValid() bool {
+	return e >= 0 && e < AnchorMax
+}
+
+// Point returns the anchor's position on an overlay of the given size
+func (e Anchor) Point(w, h int) image.Point {
+	switch e {
+	case AnchorTop:
+		return image.Point{w / 2, 0}
+	case AnchorTopRight:
+		return image.Point{w, 0}
+	case AnchorLeft:
+		return image.Point{0, h / 2}
+	case AnchorCenter:
+		return image.Point{w / 2, h / 2}
+	case AnchorRight:
+		return image.Point{w, h / 2}
+	case AnchorBottomLeft:
+		return image.Point{0, h}
+	case AnchorBottom:
+		return image.Point{w / 2, h}
+	case AnchorBottomRight:
+		return image.Point{w, h}
+	}
+	return image.Point{}
+}
+
+// RelativeRect is a rectangle stored independent of the overlay's resolution.
+// For AnchorScaled it holds fractions of the overlay size,
+// for other anchors it holds the pixel offset from the anchor and the pixel size
+type RelativeRect struct {
+	X, Y float64
+	W, H float64
+}
+
+// IsZero returns true if the rect was never captured
+func (r RelativeRect) IsZero() bool {
+	return r.W == 0 || r.H == 0
+}
+
+func (r RelativeRect) String() string {
+	return fmt.Sprintf("%.4f,%.4f,%.4f,%.4f", r.X, r.Y, r.W, r.H)
+}
+
+// ScaledRect returns rect as fractions of an area of the given size
+func ScaledRect(rect image.Rectangle, w, h int) RelativeRect {
+	return RelativeRect{
+		X: float64(rect.Min.X) / float64(w),
+		Y: float64(rect.Min.Y) / float64(h),
+		W: float64(rect.Dx()) / float64(w),
+		H: float64(rect.Dy()) / float64(h),
+	}
+}
+
+// Scale returns a rect holding fractions as pixels of an area of the given size
+func (r RelativeRect) Scale(w, h int) image.Rectangle {
+	minX := int(math.Round(r.X * float64(w)))
+	minY := int(math.Round(r.Y * float64(h)))
+	return image.Rect(minX, minY, minX+int(math.Round(r.W*float64(w))), minY+int(math.Round(r.H*float64(h))))
+}
+
+// Resolve returns a placement's rectangle on an overlay of the given size
+func (p *Placement) Resolve(w, h int) image.Rectangle {
+	r := p.Relative
+	if r.IsZero() || w <= 0 || h <= 0 {
+		if p.WindowRect == nil {
+			return image.Rectangle{}
+		}
+		return *p.WindowRect
+	}
+	if p.Anchor == AnchorScaled {
+		return r.Scale(w, h)
+	}
+	min := p.Anchor.Point(w, h).Add(image.Point{int(math.Round(r.X)), int(math.Round(r.Y))})
+	return image.Rect(min.X, min.Y, min.X+int(math.Round(r.W)), min.Y+int(math.Round(r.H)))
+}
+
+// Capture stores rect, measured on an overlay of the given size, as a placement's relative rect
+func (p *Placement) Capture(rect image.Rectangle, w, h int) {
+	if w <= 0 || h <= 0 || rect.Empty() {
+		return
+	}
+	if p.Anchor == AnchorScaled {
+		p.Relative = ScaledRect(rect, w, h)
+		return
+	}
+	offset := rect.Min.Sub(p.Anchor.Point(w, h))
+	p.Relative = RelativeRect{
+		X: float64(offset.X),
+		Y: float64(offset.Y),
+		W: float64(rect.Dx()),
+		H: float64(rect.Dy()),
+	}
+}
+
+// ClampRect keeps rect inside an overlay of the given size without changing its size
+func ClampRect(rect image.Rectangle, w, h int) image.Rectangle {
+	x := util.ClampInt(rect.Min.X, 0, w-rect.Dx())
+	y := util.ClampInt(rect.Min.Y, 0, h-rect.Dy())
+	return rect.Add(image.Point{x - rect.Min.X, y - rect.Min.Y})
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsFiltered returns true if a placement's filter rules hide the event
+func (p *Placement) IsFiltered(event *DamageEvent, playerName string) bool {
+	if p.MinDamage > 0 {
+		val, err := strconv.Atoi(event.Damage)
+		if err == nil && val < p.MinDamage {
+			return true
+		}
+	}
+
+	if p.IsSelfSkipped && event.Source == event.Target {
+		return true
+	}
+
+	if p.IsPetSkipped && (IsPetName(event.Source) || IsPetName(event.Target)) {
+		return true
+	}
+
+	// the name filters look at the other side of the fight
+	name := event.Target
+	if event.Target == playerName {
+		name = event.Source
+	}
+	if len(p.IncludeNames) > 0 && !matchAny(name, p.IncludeNames) {
+		return true
+	}
+	if matchAny(name, p.ExcludeNames) {
+		return true
+	}
+
+	if len(p.IncludeSpells) > 0 && !matchAny(event.SpellName, p.IncludeSpells) {
+		return true
+	}
+	if matchAny(event.SpellName, p.ExcludeSpells) {
+		return true
+	}
+	return false
+}
+
+// IsPetName returns true if name looks like a pet, e.g. Soandso`s pet or Soandso`s warder
+func IsPetName(name string) bool {
+	return strings.Contains(name, "`s ")
+}
+
+// matchAny does a case insensitive substring match of value against patterns
+func matchAny(value string, patterns []string) bool {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if strings.Contains(value, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,5 @@
 package common
 
-import "reflect"
-
 type Font int
 
 const (
@@ -9,15 +7,25 @@ const (
 	FontNotoSansBold42
 	FontNotoSansRegular36
 	FontNotoSansBold36
+	FontMax
 )
 
 // String returns the string representation of the font
 func (f Font) String() string {
-	typ := reflect.TypeOf(f)
-	for i := 0; i < typ.NumMethod(); i++ {
-		if typ.Method(i).Type.NumIn() == 1 && typ.Method(i).Type.In(0) == typ {
-			return typ.Method(i).Name
-		}
+	switch f {
+	case FontNotoSansRegular42:
+		return "Regular 42"
+	case FontNotoSansBold42:
+		return "Bold 42"
+	case FontNotoSansRegular36:
+		return "Regular 36"
+	case FontNotoSansBold36:
+		return "Bold 36"
 	}
 	return "unknown"
 }
+
+// IsValid returns true for a font that exists
+func (f Font) IsValid() bool {
+	return f >= 0 && f < FontMax
+}
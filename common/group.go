@@ -0,0 +1,159 @@
+package common
+
+// PlacementGroup links placements so a shared setting changed on one changes on all of them
+type PlacementGroup int
+
+const (
+	PlacementGroupNone PlacementGroup = iota
+	PlacementGroupAll
+	PlacementGroupOut
+	PlacementGroupIn
+	PlacementGroupCrits
+	PlacementGroupHits
+	PlacementGroupMisses
+	PlacementGroupTotals
+	PlacementGroupCustom
+	PlacementGroupMax
+)
+
+func (e PlacementGroup) String() string {
+	switch e {
+	case PlacementGroupNone:
+		return "None"
+	case PlacementGroupAll:
+		return "All"
+	case PlacementGroupOut:
+		return "Out"
+	case PlacementGroupIn:
+		return "In"
+	case PlacementGroupCrits:
+		return "Crits"
+	case PlacementGroupHits:
+		return "Hits"
+	case PlacementGroupMisses:
+		return "Misses"
+	case PlacementGroupTotals:
+		return "Totals"
+	case PlacementGroupCustom:
+		return "Custom"
+	}
+	return "unknown"
+}
+
+// IsValid returns true for none, custom or a preset group
+func (e PlacementGroup) IsValid() bool {
+	return e >= 0 && e < PlacementGroupMax
+}
+
+// Contains returns true if a category may be linked to the group
+func (e PlacementGroup) Contains(category PopupCategory) bool {
+	switch e {
+	case PlacementGroupAll, PlacementGroupCustom:
+		return true
+	case PlacementGroupOut:
+		return !IsIncoming(category)
+	case PlacementGroupIn:
+		return IsIncoming(category)
+	case PlacementGroupCrits:
+		switch category {
+		case PopupCategoryMeleeCritOut, PopupCategoryMeleeCritIn, PopupCategorySpellCritOut, PopupCategorySpellCritIn, PopupCategoryHealCritOut, PopupCategoryHealCritIn:
+			return true
+		}
+	case PlacementGroupHits:
+		switch category {
+		case PopupCategoryMeleeHitOut, PopupCategoryMeleeHitIn, PopupCategorySpellHitOut, PopupCategorySpellHitIn, PopupCategoryHealHitOut, PopupCategoryHealHitIn, PopupCategoryRuneHitOut, PopupCategoryRuneHitIn:
+			return true
+		}
+	case PlacementGroupMisses:
+		switch category {
+		case PopupCategoryMeleeMissOut, PopupCategoryMeleeMissIn, PopupCategorySpellMissOut, PopupCategorySpellMissIn:
+			return true
+		}
+	case PlacementGroupTotals:
+		switch category {
+		case PopupCategoryTotalDamageOut, PopupCategoryTotalDamageIn, PopupCategoryTotalHealOut, PopupCategoryTotalHealIn:
+			return true
+		}
+	}
+	return false
+}
+
+// LinkedSetting is a placement setting that is shared with the rest of its group
+type LinkedSetting int
+
+const (
+	LinkedSettingDirection LinkedSetting = iota
+	LinkedSettingFont
+	LinkedSettingColor
+	LinkedSettingDuration
+	LinkedSettingMax
+)
+
+func (e LinkedSetting) String() string {
+	switch e {
+	case LinkedSettingDirection:
+		return "Direction"
+	case LinkedSettingFont:
+		return "Font"
+	case LinkedSettingColor:
+		return "Color"
+	case LinkedSettingDuration:
+		return "Duration"
+	}
+	return "unknown"
+}
+
+// Key returns how the setting is written in a placement's overrides list
+func (e LinkedSetting) Key() string {
+	switch e {
+	case LinkedSettingDirection:
+		return "direction"
+	case LinkedSettingFont:
+		return "font"
+	case LinkedSettingColor:
+		return "color"
+	case LinkedSettingDuration:
+		return "duration"
+	}
+	return ""
+}
+
+// IsOverridden returns true if the placement keeps its own value of a setting instead of its group's
+func (p *Placement) IsOverridden(setting LinkedSetting) bool {
+	for _, key := range p.Overrides {
+		if key == setting.Key() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetOverridden sets if the placement keeps its own value of a setting instead of its group's
+func (p *Placement) SetOverridden(setting LinkedSetting, isOverridden bool) {
+	overrides := []string{}
+	for _, key := range p.Overrides {
+		if key == setting.Key() {
+			continue
+		}
+		overrides = append(overrides, key)
+	}
+	if isOverridden {
+		overrides = append(overrides, setting.Key())
+	}
+	p.Overrides = overrides
+}
+
+// CopySetting copies a linked setting from another placement
+func (p *Placement) CopySetting(from *Placement, setting LinkedSetting) {
+	switch setting {
+	case LinkedSettingDirection:
+		p.Direction = from.Direction
+	case LinkedSettingFont:
+		p.Font = from.Font
+		p.FontFace = from.FontFace
+	case LinkedSettingColor:
+		p.FontColor = from.FontColor
+	case LinkedSettingDuration:
+		p.Duration = from.Duration
+	}
+}
@@ -3,18 +3,35 @@ package common
 import (
 	"image"
 	"image/color"
+	"time"
 
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 type Placement struct {
-	IsVisible      int
-	IsTallyEnabled int
-	WindowRect     *image.Rectangle
-	FontColor      color.RGBA
-	Direction      Direction
-	Font           Font
+	IsVisible      int              `config:"is_visible"` // defaults for these come from the placement's config_default
+	IsTallyEnabled int              `config:"is_tally_enabled"`
+	WindowRect     *image.Rectangle `config:"window_rect"`
+	FontColor      color.RGBA       `config:"font_color"`
+	Direction      Direction        `config:"direction"`
+	Font           Font             `config:"font"`
+	Duration       time.Duration    `config:"duration" config_default:"4s"` // how long a popup stays on screen
+	MinDamage      int              `config:"min_damage" config_default:"0"`
+	IncludeNames   []string         `config:"include_names" config_default:""`
+	ExcludeNames   []string         `config:"exclude_names" config_default:""`
+	IncludeSpells  []string         `config:"include_spells" config_default:""`
+	ExcludeSpells  []string         `config:"exclude_spells" config_default:""`
+	IsPetSkipped   bool             `config:"is_pet_skipped" config_default:"false"`
+	IsSelfSkipped  bool             `config:"is_self_skipped" config_default:"false"`
+	IsFlurryMerged bool             `config:"is_flurry_merged" config_default:"false"` // merge hits with the same source, target and verb
+	FlurryWindow   time.Duration    `config:"flurry_window" config_default:"1s"`
+	TallyKey       TallyKey         `config:"tally_key" config_default:"0"`
+	IsTallyLabeled bool             `config:"is_tally_labeled" config_default:"false"`
+	Anchor         Anchor           `config:"anchor" config_default:"0"`
+	Relative       RelativeRect     `config:"relative" config_default:""` // WindowRect independent of the overlay size
+	Group          PlacementGroup   `config:"group" config_default:"0"`
+	Overrides      []string         `config:"overrides" config_default:""` // linked settings kept out of the group
 	// entries below are not config saved
 	Category       PopupCategory
 	FontFace       text.Face
@@ -1,7 +1,5 @@
 package common
 
-import "reflect"
-
 type PopupCategory int
 
 const (
@@ -88,6 +86,17 @@ func (e PopupCategory) String() string {
 
 }
 
+// MinSize returns the smallest a placement of this category may be resized to
+func (e PopupCategory) MinSize() (int, int) {
+	switch e {
+	case PopupCategoryTotalDamageOut, PopupCategoryTotalDamageIn, PopupCategoryTotalHealOut, PopupCategoryTotalHealIn:
+		return 200, 80
+	case PopupCategoryMeleeCritOut, PopupCategoryMeleeCritIn, PopupCategorySpellCritOut, PopupCategorySpellCritIn, PopupCategoryHealCritOut, PopupCategoryHealCritIn:
+		return 150, 100
+	}
+	return 100, 80
+}
+
 type Direction int
 
 const (
@@ -99,18 +108,59 @@ const (
 	DirectionDownLeft
 	DirectionLeft
 	DirectionUpLeft
+	DirectionMax
 )
 
 func (e Direction) String() string {
-	typ := reflect.TypeOf(e)
-	for i := 0; i < typ.NumMethod(); i++ {
-		if typ.Method(i).Type.NumIn() == 1 && typ.Method(i).Type.In(0) == typ {
-			return typ.Method(i).Name
-		}
+	switch e {
+	case DirectionUp:
+		return "Up"
+	case DirectionUpRight:
+		return "Up Right"
+	case DirectionRight:
+		return "Right"
+	case DirectionDownRight:
+		return "Down Right"
+	case DirectionDown:
+		return "Down"
+	case DirectionDownLeft:
+		return "Down Left"
+	case DirectionLeft:
+		return "Left"
+	case DirectionUpLeft:
+		return "Up Left"
 	}
 	return "unknown"
 }
 
+// IsValid returns true for one of the eight directions
+func (e Direction) IsValid() bool {
+	return e >= 0 && e < DirectionMax
+}
+
+// Vector returns the unit direction on screen, where negative y is up
+func (e Direction) Vector() (float64, float64) {
+	switch e {
+	case DirectionUp:
+		return 0, -1
+	case DirectionUpRight:
+		return 1, -1
+	case DirectionRight:
+		return 1, 0
+	case DirectionDownRight:
+		return 1, 1
+	case DirectionDown:
+		return 0, 1
+	case DirectionDownLeft:
+		return -1, 1
+	case DirectionLeft:
+		return -1, 0
+	case DirectionUpLeft:
+		return -1, -1
+	}
+	return 0, -1
+}
+
 func IsTotalDamageIn(category PopupCategory) bool {
 	return category == PopupCategoryMeleeCritIn ||
 		category == PopupCategoryMeleeHitIn ||
@@ -138,3 +188,16 @@ func IsTotalHealOut(category PopupCategory) bool {
 		category == PopupCategoryHealHitOut ||
 		category == PopupCategoryRuneHitOut
 }
+
+// IsIncoming returns true for categories of damage and heals landing on the player
+func IsIncoming(category PopupCategory) bool {
+	switch category {
+	case PopupCategoryMeleeCritIn, PopupCategoryMeleeHitIn, PopupCategoryMeleeMissIn,
+		PopupCategorySpellCritIn, PopupCategorySpellHitIn, PopupCategorySpellMissIn,
+		PopupCategoryHealCritIn, PopupCategoryHealHitIn,
+		PopupCategoryRuneHitIn,
+		PopupCategoryTotalDamageIn, PopupCategoryTotalHealIn:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package common
+
+// TallyKey decides which events add up into the same tally popup
+type TallyKey int
+
+const (
+	TallyKeyCategory TallyKey = iota
+	TallyKeyTarget
+	TallyKeySpell
+	TallyKeyTargetSpell
+	TallyKeyMax
+)
+
+func (e TallyKey) String() string {
+	switch e {
+	case TallyKeyCategory:
+		return "Category"
+	case TallyKeyTarget:
+		return "Target"
+	case TallyKeySpell:
+		return "Spell"
+	case TallyKeyTargetSpell:
+		return "Target + Spell"
+	}
+	return "unknown"
+}
+
+// IsValid returns true for a known tally key
+func (e TallyKey) IsValid() bool {
+	return e >= 0 && e < TallyKeyMax
+}
+
+// Key returns the identity an event is tallied under, events with the same key share a popup
+func (e TallyKey) Key(event *DamageEvent) string {
+	switch e {
+	case TallyKeyTarget:
+		return event.Target
+	case TallyKeySpell:
+		return event.SpellName
+	case TallyKeyTargetSpell:
+		return event.Target + "\x00" + event.SpellName
+	}
+	return ""
+}
+
+// Label returns the text shown in front of a tally, e.g. the target or spell name
+func (e TallyKey) Label(event *DamageEvent) string {
+	switch e {
+	case TallyKeyTarget:
+		return event.Target
+	case TallyKeySpell:
+		return event.SpellName
+	case TallyKeyTargetSpell:
+		if event.SpellName == "" {
+			return event.Target
+		}
+		return event.SpellName + " on " + event.Target
+	}
+	return event.Category.String()
+}
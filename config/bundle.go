@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+// bundlePrefix starts a compact layout string, the number is bumped if the encoding ever changes
+const bundlePrefix = "csl1:"
+
+// maxBundleSize caps a decompressed layout, a real one is a few KiB, so a small paste can't inflate into gigabytes
+const maxBundleSize = 1 << 20
+
+// bundlePrefixes are the top level keys, besides placements, that belong in a shared layout.
+// Paths, the window position and profile bindings are specific to one machine and are left out
+var bundlePrefixes = []string{"popup_", "number_", "placement_snap_"}
+
+// isBundleKey returns true if a key, or the parent of a sub key, is part of a shared layout
+func (c *CritSprinklerConfiguration) isBundleKey(key string) bool {
+	base, _, _ := strings.Cut(key, ".")
+	if _, ok := c.placementField(base); ok {
+		return true
+	}
+	for _, prefix := range bundlePrefixes {
+		if strings.HasPrefix(base, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportBundle returns the layout settings that differ from the defaults, as ini lines
+func (c *CritSprinklerConfiguration) ExportBundle() (string, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	defaults := &CritSprinklerConfiguration{}
+	err := defaults.resetBundleDefaults()
+	if err != nil {
+		return "", fmt.Errorf("defaults: %w", err)
+	}
+
+	out := fmt.Sprintf("config_version = %d\n", configVersion)
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || !c.isBundleKey(sKey) {
+			continue
+		}
+		lines, err := formatKey(sKey, reflect.ValueOf(c).Elem().Field(i))
+		if err != nil {
+			return "", err
+		}
+		defaultLines, err := formatKey(sKey, reflect.ValueOf(defaults).Elem().Field(i))
+		if err != nil {
+			return "", err
+		}
+		isDefault := map[string]bool{}
+		for _, line := range strings.Split(defaultLines, "\n") {
+			isDefault[line] = true
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(lines, "\n"), "\n") {
+			if isDefault[line] {
+				continue
+			}
+			out += line + "\n"
+		}
+	}
+	return out, nil
+}
+
+// EncodeBundle compresses exported layout lines into a single line that is easy to paste in chat
+func EncodeBundle(text string) (string, error) {
+	buf := &bytes.Buffer{}
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		return "", fmt.Errorf("new writer: %w", err)
+	}
+	_, err = w.Write([]byte(text))
+	if err != nil {
+		return "", fmt.Errorf("write: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return "", fmt.Errorf("close: %w", err)
+	}
+	return bundlePrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeBundle returns the layout lines of a compact layout string. Anything else is returned as is,
+// so a layout can also be imported from a plain ini file
+func DecodeBundle(data string) (string, error) {
+	data = strings.TrimSpace(data)
+	if !strings.HasPrefix(data, bundlePrefix) {
+		return data, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(data, bundlePrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode: %w", err)
+	}
+	// one byte past the cap tells a layout of exactly maxBundleSize from a larger one
+	text, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(raw)), maxBundleSize+1))
+	if err != nil {
+		return "", fmt.Errorf("decompress: %w", err)
+	}
+	if len(text) > maxBundleSize {
+		return "", fmt.Errorf("decompress: layout is larger than %d bytes", maxBundleSize)
+	}
+	return string(text), nil
+}
+
+// PreviewBundle returns a copy of the config with a shared layout applied, c itself is left alone.
+// Layout settings missing from the bundle are at their default, everything else is kept from c.
+// Lines that can't be used keep c's value and are returned as problems
+func (c *CritSprinklerConfiguration) PreviewBundle(data string) (*CritSprinklerConfiguration, []Problem, error) {
+	text, err := DecodeBundle(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, err := readKeyValues(strings.NewReader(text))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read: %w", err)
+	}
+	version, versionLine, err := versionOf(values)
+	if err != nil {
+		return nil, nil, fmt.Errorf("line %d config_version %s: %w", versionLine.line, versionLine.value, err)
+	}
+	values, err = migrate(values, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := c.Clone()
+	current := c.Clone()
+	err = next.resetBundleDefaults()
+	if err != nil {
+		return nil, nil, fmt.Errorf("defaults: %w", err)
+	}
+
+	problems := []Problem{}
+	layoutCount := 0
+	for _, kv := range values {
+		if kv.key == "config_version" {
+			// an export of the default layout has nothing but its version
+			layoutCount++
+			continue
+		}
+		if !next.isBundleKey(kv.key) {
+			fmt.Println("layout line", kv.line, "ignoring key", kv.key)
+			continue
+		}
+		layoutCount++
+		err = next.set(kv.key, kv.value)
+		if err != nil {
+			problems = append(problems, Problem{Line: kv.line, Key: kv.key, Value: kv.value, Err: err})
+			// keep what the key was before the import
+			_ = next.set(kv.key, current.get(kv.key))
+		}
+	}
+	if layoutCount == 0 {
+		return nil, nil, fmt.Errorf("no layout settings found")
+	}
+	return next, problems, nil
+}
+
+// get returns the config representation of a key or parent.child sub key
+func (c *CritSprinklerConfiguration) get(key string) string {
+	base, sub, isSub := strings.Cut(key, ".")
+	field, ok := c.field(base)
+	if !ok {
+		return ""
+	}
+	if isSub {
+		if field.Kind() != reflect.Struct {
+			return ""
+		}
+		field, ok = subField(field, sub)
+		if !ok {
+			return ""
+		}
+	}
+	value, err := formatValue(field)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// resetBundleDefaults sets every layout setting to its default
+func (c *CritSprinklerConfiguration) resetBundleDefaults() error {
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || !c.isBundleKey(sKey) {
+			continue
+		}
+		err := c.resetDefault(sKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sKey, err)
+		}
+		field := reflect.ValueOf(c).Elem().Field(i)
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+		err = resetStructDefaults(field)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sKey, err)
+		}
+	}
+	return nil
+}
+
+// Clone returns a copy of the config that can be changed without touching c,
+// placements get their own window rect
+func (c *CritSprinklerConfiguration) Clone() *CritSprinklerConfiguration {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := *c
+	for i := range reflect.TypeOf(out).NumField() {
+		field := reflect.ValueOf(&out).Elem().Field(i)
+		if field.Type() != reflect.TypeOf(common.Placement{}) {
+			continue
+		}
+		placement := field.Addr().Interface().(*common.Placement)
+		if placement.WindowRect == nil {
+			continue
+		}
+		rect := image.Rectangle(*placement.WindowRect)
+		placement.WindowRect = &rect
+	}
+	return &out
+}
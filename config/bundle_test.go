@@ -0,0 +1,111 @@
+package config
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"image"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	err := SetPath(filepath.Join(t.TempDir(), fileName))
+	if err != nil {
+		t.Fatalf("setPath: %v", err)
+	}
+	src, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	text, err := src.ExportBundle()
+	if err != nil {
+		t.Fatalf("export defaults: %v", err)
+	}
+	if strings.TrimSpace(text) != "config_version = 2" {
+		t.Errorf("export defaults: got %q, want only the version", text)
+	}
+
+	src.LogPath = `c:\eq\logs\eqlog_Xack_test.txt`
+	*src.MeleeCritOut.WindowRect = image.Rect(100, 100, 400, 250)
+	src.MeleeCritOut.Direction = common.DirectionUpLeft
+	src.MeleeCritOut.Duration = 2 * time.Second
+	src.PopupMaxPerPlacement = 6
+
+	text, err = src.ExportBundle()
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if strings.Contains(text, "log_path") {
+		t.Errorf("export: log_path should be left out of a layout:\n%s", text)
+	}
+	data, err := EncodeBundle(text + "melee_hit_in.direction = 42\n")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	dst, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	dst.LogPath = `c:\eq\logs\eqlog_Other_test.txt`
+	dst.MeleeHitOut.Duration = 9 * time.Second
+
+	next, problems, err := dst.PreviewBundle(data)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Key != "melee_hit_in.direction" {
+		t.Errorf("problems: got %v, want melee_hit_in.direction", problems)
+	}
+	if *next.MeleeCritOut.WindowRect != image.Rect(100, 100, 400, 250) {
+		t.Errorf("window rect: got %v", *next.MeleeCritOut.WindowRect)
+	}
+	if next.MeleeCritOut.Direction != common.DirectionUpLeft || next.MeleeCritOut.Duration != 2*time.Second {
+		t.Errorf("melee_crit_out: got direction %v duration %v", next.MeleeCritOut.Direction, next.MeleeCritOut.Duration)
+	}
+	if next.PopupMaxPerPlacement != 6 {
+		t.Errorf("popup_max_per_placement: got %d, want 6", next.PopupMaxPerPlacement)
+	}
+	if next.MeleeHitOut.Duration != 4*time.Second {
+		t.Errorf("melee_hit_out.duration: got %v, want the 4s default", next.MeleeHitOut.Duration)
+	}
+	if next.LogPath != dst.LogPath {
+		t.Errorf("log_path: got %s, want it kept from the importer", next.LogPath)
+	}
+	if dst.MeleeHitOut.Duration != 9*time.Second || dst.MeleeCritOut.Direction == common.DirectionUpLeft {
+		t.Errorf("preview changed the config it was called on")
+	}
+
+	_, _, err = dst.PreviewBundle("just some chat text")
+	if err == nil {
+		t.Errorf("preview of chat text: expected an error")
+	}
+}
+
+func TestDecodeBundleLimit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := flate.NewWriter(buf, flate.BestCompression)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+	_, err = w.Write(bytes.Repeat([]byte("#"), maxBundleSize+1))
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// a few KiB of paste that inflates past the cap
+	_, err = DecodeBundle(bundlePrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()))
+	if err == nil {
+		t.Fatalf("expected an error for a bundle over %d bytes", maxBundleSize)
+	}
+}
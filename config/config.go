@@ -1,7 +1,7 @@
 package config
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -15,7 +15,7 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/xackery/critsprinkler/common"
-	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/util"
 )
 
 const (
@@ -27,10 +27,15 @@ var (
 )
 
 type CritSprinklerConfiguration struct {
-	IsNew      bool
-	LogPath    string          `config:"log_path" config_default:""`
-	EQPath     string          `config:"eq_path" config_default:""`
-	MainWindow image.Rectangle `config:"main_window"`
+	IsNew         bool
+	Problems      []Problem       // lines of the file that could not be used when it was loaded
+	ConfigVersion int             `config:"config_version" config_default:"2"` // format of the file, older files are migrated on load
+	LogPath       string          `config:"log_path" config_default:""`
+	EQPath        string          `config:"eq_path" config_default:""`
+	MainWindow    image.Rectangle `config:"main_window"`
+	MainMonitor   string          `config:"main_monitor" config_default:""` // name of the monitor the main window is restored to
+
+	MainWindowRelative common.RelativeRect `config:"main_window_relative" config_default:""` // MainWindow as fractions of the monitor size
 
 	MeleeHitOut    common.Placement `config:"melee_hit_out" config_default:"0,1,220,307,420,407,255,0,255,255,0,2"`
 	MeleeHitIn     common.Placement `config:"melee_hit_in" config_default:"0,1,220,307,420,407,255,0,255,255,0,2"`
@@ -55,12 +60,35 @@ type CritSprinklerConfiguration struct {
 	TotalHealIn    common.Placement `config:"total_heal_in" config_default:"1,1,220,307,420,407,255,0,255,255,0,2"`
 	TotalHealOut   common.Placement `config:"total_heal_out" config_default:"1,1,220,307,420,407,255,0,255,255,0,2"`
 	Money          common.Placement `config:"money" config_default:"1,0,220,307,420,407,255,0,255,255,0,2"`
+	Exp            common.Placement `config:"exp" config_default:"0,0,440,307,700,407,255,255,255,255,0,2"`
 
 	IsFullscreenBorderless bool          `config:"is_fullscreen_borderless" config_default:"false"`
-	IsCommaEnabled         bool          `config:"is_comma_enabled" config_default:"true"`
-	PopupTallyDuration     time.Duration `config:"popup_tally_duration" config_default:"5000000000"`
+	PopupTallyDuration     time.Duration `config:"popup_tally_duration" config_default:"5s"`
+	PopupMaxPerPlacement   int           `config:"popup_max_per_placement" config_default:"12"`
+	PopupIsOverflowMerged  bool          `config:"popup_is_overflow_merged" config_default:"true"`
+	PlacementSnapGrid      int           `config:"placement_snap_grid" config_default:"10"`    // 0 disables grid snapping
+	PlacementSnapDistance  int           `config:"placement_snap_distance" config_default:"8"` // how close an edge must be to a guide to snap
+
+	MoneyMaxSprinkles         int           `config:"money_max_sprinkles" config_default:"300"`         // coins past this count straight away
+	MoneyIsCollectAnimated    bool          `config:"money_is_collect_animated" config_default:"false"` // resting coins fly to their counter instead of melting
+	MoneyIsBounceSoundEnabled bool          `config:"money_is_bounce_sound_enabled" config_default:"true"`
+	MoneyBounceSoundInterval  time.Duration `config:"money_bounce_sound_interval" config_default:"80ms"` // least time between bounce sounds
+
+	NumberSeparator         util.NumberSeparator    `config:"number_separator" config_default:"1"`
+	NumberAbbreviation      util.NumberAbbreviation `config:"number_abbreviation" config_default:"0"`
+	NumberSignificantDigits util.SignificantDigits  `config:"number_significant_digits" config_default:"3"`
+
+	Profile           string   `config:"profile" config_default:""`
+	ProfileCharacters []string `config:"profile_characters" config_default:""` // character:profile pairs
+}
 
-	PopupIsCommaEnabled bool `config:"popup_is_comma_enabled" config_default:"true"`
+// NumberFormat returns the number format used by popups, tallies and counters
+func (c *CritSprinklerConfiguration) NumberFormat() util.NumberFormat {
+	return util.NumberFormat{
+		Separator:         c.NumberSeparator,
+		Abbreviation:      c.NumberAbbreviation,
+		SignificantDigits: c.NumberSignificantDigits,
+	}
 }
 
 // FileName returns the config file name
@@ -72,10 +100,7 @@ func FileName() string {
 func LoadCritSprinklerConfig() (*CritSprinklerConfiguration, error) {
 	mu.Lock()
 	defer mu.Unlock()
-	knownKeys := []string{}
-	exePath := os.Args[0]
-	path := filepath.Dir(exePath)
-	path = filepath.Join(path, fileName)
+	path := configPath()
 
 	_, err := os.Stat(path)
 	if err != nil {
@@ -107,6 +132,11 @@ func LoadCritSprinklerConfig() (*CritSprinklerConfiguration, error) {
 			}
 		}
 
+		err = config.resetSubDefaults()
+		if err != nil {
+			return nil, fmt.Errorf("set default sub values: %w", err)
+		}
+
 		return config, nil
 	}
 
@@ -116,277 +146,107 @@ func LoadCritSprinklerConfig() (*CritSprinklerConfiguration, error) {
 	}
 	defer r.Close()
 
-	var config CritSprinklerConfiguration
+	values, err := readKeyValues(r)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	problems := []Problem{}
+	version, versionLine, err := versionOf(values)
+	if err != nil {
+		// named keys are untouched by migrations, so treating it as the oldest version is safe
+		problems = append(problems, Problem{Line: versionLine.line, Key: versionLine.key, Value: versionLine.value, Err: err})
+		version = 1
+	}
+	if version < configVersion {
+		err = backup(path, version)
+		if err != nil {
+			return nil, fmt.Errorf("backup version %d: %w", version, err)
+		}
+	}
+	values, err = migrate(values, version)
+	if err != nil {
+		return nil, err
+	}
 
-	foundKeys := map[string]bool{}
+	var config CritSprinklerConfiguration
 
-	for i := range reflect.TypeOf(config).NumField() {
-		sKey, ok := reflect.StructTag(reflect.TypeOf(config).Field(i).Tag).Lookup("config")
-		if !ok {
-			continue
+	// every key starts at its default, so keys missing from the file keep working
+	for _, key := range knownKeys(&config) {
+		err = config.resetDefault(key)
+		if err != nil {
+			return nil, fmt.Errorf("set default value for %s: %w", key, err)
 		}
-
-		knownKeys = append(knownKeys, sKey)
+	}
+	err = config.resetSubDefaults()
+	if err != nil {
+		return nil, fmt.Errorf("set default sub values: %w", err)
 	}
 
-	lineNumber := 0
-	reader := bufio.NewScanner(r)
-	for reader.Scan() {
-		line := reader.Text()
-		lineNumber++
-		if strings.HasPrefix(line, "#") {
+	// a bad value only costs its own key, which goes back to the default
+	for _, kv := range values {
+		// already read, and reported if bad, by versionOf
+		if kv.key == "config_version" {
 			continue
 		}
-		if strings.Contains(line, "=") {
-			parts := strings.Split(line, "=")
-			if len(parts) != 2 {
-				continue
-			}
-			key := strings.ToLower(strings.TrimSpace(parts[0]))
-			value := strings.TrimSpace(parts[1])
-			// reflect struct tags of cfg for config and see if they match key
-
-			isKnown := false
-			for i := range reflect.TypeOf(config).NumField() {
-				sKey, ok := reflect.StructTag(reflect.TypeOf(config).Field(i).Tag).Lookup("config")
-				if !ok {
-					continue
-				}
-
-				if sKey != key {
-					continue
-				}
-
-				if strings.HasSuffix(key, "_color") {
-					base := strings.TrimSuffix(key, "_color")
-					for i := range reflect.TypeOf(config).NumField() {
-						sKey, ok := reflect.StructTag(reflect.TypeOf(config).Field(i).Tag).Lookup("config")
-						if !ok {
-							continue
-						}
-
-						if sKey != base {
-							continue
-						}
-
-						field := reflect.ValueOf(&config).Elem().Field(i)
-						rgba := color.RGBA{}
-						parts := strings.Split(value, ",")
-						if len(parts) != 4 {
-							return nil, fmt.Errorf("line %d parse %s=%s to color.RGBA: invalid number of parts", lineNumber, key, value)
-						}
-						for i := range parts {
-							val, err := strconv.Atoi(parts[i])
-							if err != nil {
-								return nil, fmt.Errorf("line %d parse %s=%s to color.RGBA: %w", lineNumber, key, value, err)
-							}
-
-							switch i {
-							case 0:
-								rgba.R = uint8(val)
-							case 1:
-								rgba.G = uint8(val)
-							case 2:
-								rgba.B = uint8(val)
-							case 3:
-								rgba.A = uint8(val)
-							}
-						}
-						field.Set(reflect.ValueOf(rgba))
-						isKnown = true
-						foundKeys[key] = true
-					}
-				}
-
-				field := reflect.ValueOf(&config).Elem().Field(i)
-				switch field.Kind() {
-				case reflect.Int:
-					val, err := strconv.Atoi(value)
-					if err != nil {
-						return nil, fmt.Errorf("line %d parse %s=%s to int: %w", lineNumber, key, value, err)
-					}
-
-					field.SetInt(int64(val))
-				case reflect.String:
-					field.SetString(value)
-				case reflect.Bool:
-					val, err := strconv.ParseBool(value)
-					if err != nil {
-						return nil, fmt.Errorf("line %d parse %s=%s to bool: %w", lineNumber, key, value, err)
-					}
-
-					field.SetBool(val)
-				case reflect.Int64:
-					val, err := strconv.ParseInt(value, 10, 64)
-					if err != nil {
-						return nil, fmt.Errorf("line %d parse %s=%s to int64: %w", lineNumber, key, value, err)
-					}
-
-					field.SetInt(val)
-
-				case reflect.Struct:
-					switch field.Interface().(type) {
-					case image.Rectangle:
-						parts := strings.Split(value, ",")
-						if len(parts) != 4 {
-							return nil, fmt.Errorf("line %d parse %s=%s to image.Rectangle: invalid number of parts", lineNumber, key, value)
-						}
-
-						var rect image.Rectangle
-						for i := range parts {
-							val, err := strconv.Atoi(parts[i])
-							if err != nil {
-								return nil, fmt.Errorf("line %d parse %s=%s to image.Rectangle: %w", lineNumber, key, value, err)
-							}
-
-							switch i {
-							case 0:
-								rect.Min.X = val
-							case 1:
-								rect.Min.Y = val
-							case 2:
-								rect.Max.X = val
-							case 3:
-								rect.Max.Y = val
-							}
-						}
-
-						field.Set(reflect.ValueOf(rect))
-					case color.RGBA:
-						parts := strings.Split(value, ",")
-						if len(parts) != 4 {
-							return nil, fmt.Errorf("line %d parse %s=%s to color.RGBA: invalid number of parts", lineNumber, key, value)
-						}
-
-						var rgba color.RGBA
-						for i := range parts {
-							val, err := strconv.Atoi(parts[i])
-							if err != nil {
-								return nil, fmt.Errorf("line %d parse %s=%s to color.RGBA: %w", lineNumber, key, value, err)
-							}
-
-							switch i {
-							case 0:
-								rgba.R = uint8(val)
-							case 1:
-								rgba.G = uint8(val)
-							case 2:
-								rgba.B = uint8(val)
-							case 3:
-								rgba.A = uint8(val)
-							}
-						}
-
-						field.Set(reflect.ValueOf(rgba))
-					case common.Direction:
-						val, err := strconv.Atoi(value)
-						if err != nil {
-							return nil, fmt.Errorf("line %d parse %s=%s to common.Direction: %w", lineNumber, key, value, err)
-						}
-						field.Set(reflect.ValueOf(common.Direction(val)))
-					case common.Placement:
-						parts := strings.Split(value, ",")
-						if len(parts) == 4 {
-							dialog.MsgBox("New Version", "CritSprinkler had a big change, so settings will be reset. Be sure to save your settings!")
-							for i := range reflect.TypeOf(config).NumField() {
-								sKey, ok := reflect.StructTag(reflect.TypeOf(config).Field(i).Tag).Lookup("config")
-								if !ok {
-									continue
-								}
-
-								err = config.resetDefault(sKey)
-								if err != nil {
-									return nil, fmt.Errorf("set default value for %s: %w", sKey, err)
-								}
-							}
-							return &config, nil
-						}
-						windowRect := &image.Rectangle{}
-						rgba := color.RGBA{}
-						for i := 0; i < 11; i++ {
-							val, err := strconv.Atoi(parts[i])
-							if err != nil {
-								return nil, fmt.Errorf("line %d parse %s=%s to image.Rectangle: %w", lineNumber, key, value, err)
-							}
-
-							switch i {
-							case 0: // IsVisible
-								field.Field(i).SetInt(int64(val))
-							case 1: // IsTallyEnabled
-								field.Field(i).SetInt(int64(val))
-							case 2: // WindowRect
-								windowRect.Min.X = val
-							case 3:
-								windowRect.Min.Y = val
-							case 4:
-								windowRect.Max.X = val
-							case 5:
-								windowRect.Max.Y = val
-								field.Field(2).Set(reflect.ValueOf(windowRect))
-							case 6: // FontColor
-								rgba.R = uint8(val)
-							case 7:
-								rgba.G = uint8(val)
-							case 8:
-								rgba.B = uint8(val)
-							case 9:
-								rgba.A = uint8(val)
-								field.Field(3).Set(reflect.ValueOf(rgba))
-							case 10: // Direction
-								field.Field(4).Set(reflect.ValueOf(common.Direction(val)))
-							case 11: // Font
-								field.Field(5).Set(reflect.ValueOf(common.Font(val)))
-							}
-						}
-
-					default:
-						return nil, fmt.Errorf("line %d unknown struct type %s", lineNumber, field.Kind())
-					}
-				default:
-					return nil, fmt.Errorf("line %d unknown type %s", lineNumber, field.Kind())
-				}
-				isKnown = true
-				foundKeys[key] = true
-			}
+		err = config.set(kv.key, kv.value)
+		if err == nil {
+			continue
+		}
+		problem := Problem{Line: kv.line, Key: kv.key, Value: kv.value, Err: err}
+		fmt.Println("config", problem.String())
+		problems = append(problems, problem)
+		if errors.Is(err, errUnknownKey) {
+			continue
+		}
+		err = config.resetKey(kv.key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d set default value for %s: %w", kv.line, kv.key, err)
+		}
+	}
+	config.ConfigVersion = configVersion
+	config.Problems = problems
 
-			if !isKnown {
-				fmt.Println("line", lineNumber, "unknown key", key)
-				//return nil, fmt.Errorf("line %d unknown key %s", lineNumber, key)
-				err = config.resetDefault(key)
-				if err != nil {
-					return nil, fmt.Errorf("line %d set default value for %s: %w", lineNumber, key, err)
-				}
+	return &config, nil
+}
 
-			}
+// knownKeys returns every top level config key
+func knownKeys(c *CritSprinklerConfiguration) []string {
+	keys := []string{}
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok {
+			continue
 		}
+		keys = append(keys, sKey)
 	}
+	return keys
+}
 
-	if len(foundKeys) != len(knownKeys) {
-		for i := range knownKeys {
-			if !foundKeys[knownKeys[i]] {
-				fmt.Println("missing key", knownKeys[i])
-				err = config.resetDefault(knownKeys[i])
-				if err != nil {
-					return nil, fmt.Errorf("set default value for %s: %w", knownKeys[i], err)
-				}
-			}
+// field returns the field tagged with a top level key
+func (c *CritSprinklerConfiguration) field(key string) (reflect.Value, bool) {
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || sKey != key {
+			continue
 		}
+		return reflect.ValueOf(c).Elem().Field(i), true
 	}
-
-	return &config, nil
+	return reflect.Value{}, false
 }
 
 // Save saves the config
 func (c *CritSprinklerConfiguration) Save() error {
-	exePath := os.Args[0]
-	path := filepath.Dir(exePath)
-	path = filepath.Join(path, fileName)
+	path := configPath()
 
 	fi, err := os.Stat(path)
 	if err != nil {
 		if !os.IsNotExist(err) {
 			return fmt.Errorf("stat %s: %w", fileName, err)
 		}
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return fmt.Errorf("create config dir: %w", err)
+		}
 		w, err := os.Create(path)
 		if err != nil {
 			return fmt.Errorf("create %s: %w", fileName, err)
@@ -405,38 +265,18 @@ func (c *CritSprinklerConfiguration) Save() error {
 			continue
 		}
 
-		field := reflect.ValueOf(c).Elem().Field(i)
-		switch field.Kind() {
-		case reflect.Int:
-			out += fmt.Sprintf("%s = %d\n", sKey, field.Int())
-		case reflect.String:
-			out += fmt.Sprintf("%s = %s\n", sKey, field.String())
-		case reflect.Bool:
-			out += fmt.Sprintf("%s = %t\n", sKey, field.Bool())
-		case reflect.Int64:
-			out += fmt.Sprintf("%s = %d\n", sKey, field.Int())
-		case reflect.Struct:
-			switch field.Interface().(type) {
-			case image.Rectangle:
-				rect := field.Interface().(image.Rectangle)
-				out += fmt.Sprintf("%s = %d,%d,%d,%d\n", sKey, rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y)
-			case color.RGBA:
-				rgba := field.Interface().(color.RGBA)
-				out += fmt.Sprintf("%s = %d,%d,%d,%d\n", sKey, rgba.R, rgba.G, rgba.B, rgba.A)
-			case common.Direction:
-				out += fmt.Sprintf("%s = %d\n", sKey, field.Interface().(common.Direction))
-			case common.Placement:
-				placement := field.Interface().(common.Placement)
-				out += fmt.Sprintf("%s = %d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d,%d\n", sKey, placement.IsVisible, placement.IsTallyEnabled, placement.WindowRect.Min.X, placement.WindowRect.Min.Y, placement.WindowRect.Max.X, placement.WindowRect.Max.Y, placement.FontColor.R, placement.FontColor.G, placement.FontColor.B, placement.FontColor.A, placement.Direction, placement.Font)
-			default:
-				return fmt.Errorf("unknown struct type %s", field.Kind())
-			}
-		default:
-			return fmt.Errorf("unknown type %s", field.Kind())
+		lines, err := formatKey(sKey, reflect.ValueOf(c).Elem().Field(i))
+		if err != nil {
+			return err
 		}
+		out += lines
 	}
 
+	// the write and its mod time are recorded under one lock, so Watch can't see our own save as an edit
+	mu.Lock()
 	err = os.WriteFile(path, []byte(out), 0644)
+	savedModTime = modTime()
+	mu.Unlock()
 	if err != nil {
 		return fmt.Errorf("write file: %w", err)
 	}
@@ -444,6 +284,35 @@ func (c *CritSprinklerConfiguration) Save() error {
 	return nil
 }
 
+// formatKey returns the ini lines of a key, followed by its sub keys, e.g. melee_hit_out.duration
+func formatKey(sKey string, field reflect.Value) (string, error) {
+	out := ""
+	// placements are written as named sub keys only
+	if field.Type() != reflect.TypeOf(common.Placement{}) {
+		value, err := formatValue(field)
+		if err != nil {
+			return "", fmt.Errorf("format %s: %w", sKey, err)
+		}
+		out = fmt.Sprintf("%s = %s\n", sKey, value)
+	}
+
+	if field.Kind() != reflect.Struct {
+		return out, nil
+	}
+	for j := range field.NumField() {
+		subKey, ok := field.Type().Field(j).Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		value, err := formatValue(field.Field(j))
+		if err != nil {
+			return "", fmt.Errorf("format %s.%s: %w", sKey, subKey, err)
+		}
+		out += fmt.Sprintf("%s.%s = %s\n", sKey, subKey, value)
+	}
+	return out, nil
+}
+
 // resetDefault sets a default value for a key based on config_default
 func (c *CritSprinklerConfiguration) resetDefault(key string) error {
 	for i := range reflect.TypeOf(*c).NumField() {
@@ -456,140 +325,345 @@ func (c *CritSprinklerConfiguration) resetDefault(key string) error {
 			continue
 		}
 
-		if reflect.TypeOf(*c).Field(i).Tag.Get("config_default") == "" {
+		def := reflect.TypeOf(*c).Field(i).Tag.Get("config_default")
+		if def == "" {
+			continue
+		}
+
+		field := reflect.ValueOf(c).Elem().Field(i)
+		err := setValue(field, def)
+		if err != nil {
+			return fmt.Errorf("parse default %s: %w", def, err)
+		}
+	}
+
+	return nil
+}
+
+// resetSubDefaults sets every sub key, e.g. melee_hit_out.duration, to its config_default
+func (c *CritSprinklerConfiguration) resetSubDefaults() error {
+	for i := range reflect.TypeOf(*c).NumField() {
+		field := reflect.ValueOf(c).Elem().Field(i)
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+		err := resetStructDefaults(field)
+		if err != nil {
+			return fmt.Errorf("%s: %w", reflect.TypeOf(*c).Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+// resetStructDefaults sets tagged fields of a struct value to their config_default
+func resetStructDefaults(field reflect.Value) error {
+	for j := range field.NumField() {
+		subField := field.Type().Field(j)
+		if _, ok := subField.Tag.Lookup("config"); !ok {
+			continue
+		}
+		def, ok := subField.Tag.Lookup("config_default")
+		if !ok {
 			continue
 		}
+		err := setValue(field.Field(j), def)
+		if err != nil {
+			return fmt.Errorf("parse default %s for %s: %w", def, subField.Name, err)
+		}
+	}
+	return nil
+}
 
+// setSubValue sets a key in the form parent.child, e.g. melee_hit_out.duration
+func (c *CritSprinklerConfiguration) setSubValue(key string, value string) error {
+	base, sub, _ := strings.Cut(key, ".")
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || sKey != base {
+			continue
+		}
 		field := reflect.ValueOf(c).Elem().Field(i)
-		switch field.Kind() {
-		case reflect.Int:
-			val, err := strconv.Atoi(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"))
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("%s has no sub keys", base)
+		}
+		subField, ok := subField(field, sub)
+		if !ok {
+			return errUnknownKey
+		}
+		return setValue(subField, value)
+	}
+	return errUnknownKey
+}
+
+// setValue parses value into field based on the field's type
+func setValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Int:
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("to int: %w", err)
+		}
+		err = checkRange(field.Type(), val)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(val))
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("to bool: %w", err)
+		}
+		field.SetBool(val)
+	case reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			val, err := time.ParseDuration(value)
 			if err != nil {
-				return fmt.Errorf("parse %s to int: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
+				return fmt.Errorf("to duration: %w", err)
+			}
+			if val < 0 {
+				return fmt.Errorf("duration %s is negative", val)
 			}
 			field.SetInt(int64(val))
-		case reflect.String:
-			field.SetString(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"))
-		case reflect.Bool:
-			val, err := strconv.ParseBool(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"))
+			return nil
+		}
+		val, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("to int64: %w", err)
+		}
+		field.SetInt(val)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unknown slice type %s", field.Type())
+		}
+		field.Set(reflect.ValueOf(util.SplitList(value)))
+	case reflect.Pointer:
+		if field.Type() != reflect.TypeOf(&image.Rectangle{}) {
+			return fmt.Errorf("unknown pointer type %s", field.Type())
+		}
+		rect, err := parseRectangle(value)
+		if err != nil {
+			return fmt.Errorf("to image.Rectangle: %w", err)
+		}
+		field.Set(reflect.ValueOf(&rect))
+	case reflect.Struct:
+		switch field.Interface().(type) {
+		case image.Rectangle:
+			rect, err := parseRectangle(value)
 			if err != nil {
-				return fmt.Errorf("parse %s to bool: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
+				return fmt.Errorf("to image.Rectangle: %w", err)
 			}
-			field.SetBool(val)
-		case reflect.Int64:
-			val, err := strconv.ParseInt(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), 10, 64)
+			field.Set(reflect.ValueOf(rect))
+		case common.RelativeRect:
+			rect, err := parseRelativeRect(value)
 			if err != nil {
-				return fmt.Errorf("parse %s to int64: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
+				return fmt.Errorf("to common.RelativeRect: %w", err)
 			}
-			field.SetInt(val)
-
-		case reflect.Struct:
-			switch field.Interface().(type) {
-			case image.Rectangle:
-				parts := strings.Split(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), ",")
-				if len(parts) != 4 {
-					return fmt.Errorf("parse %s to image.Rectangle: invalid number of parts", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"))
-				}
-
-				var rect image.Rectangle
-				for i := range parts {
-					val, err := strconv.Atoi(parts[i])
-					if err != nil {
-						return fmt.Errorf("parse %s to image.Rectangle: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
-					}
-
-					switch i {
-					case 0:
-						rect.Min.X = val
-					case 1:
-						rect.Min.Y = val
-					case 2:
-						rect.Max.X = val
-					case 3:
-						rect.Max.Y = val
-					}
-				}
-
-				field.Set(reflect.ValueOf(rect))
-			case color.RGBA:
-				parts := strings.Split(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), ",")
-				if len(parts) != 4 {
-					return fmt.Errorf("parse %s to color.RGBA: invalid number of parts", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"))
-				}
-
-				var rgba color.RGBA
-				for i := range parts {
-					val, err := strconv.Atoi(parts[i])
-					if err != nil {
-						return fmt.Errorf("parse %s to color.RGBA: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
-					}
-
-					switch i {
-					case 0:
-						rgba.R = uint8(val)
-					case 1:
-						rgba.G = uint8(val)
-					case 2:
-						rgba.B = uint8(val)
-					case 3:
-						rgba.A = uint8(val)
-					}
-				}
-
-				field.Set(reflect.ValueOf(rgba))
-			case common.Direction:
-				val, err := strconv.Atoi(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"))
-				if err != nil {
-					return fmt.Errorf("parse %s to common.Direction: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
-				}
-				field.Set(reflect.ValueOf(common.Direction(val)))
-			case common.Placement:
-				parts := strings.Split(reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), ",")
-				windowRect := &image.Rectangle{}
-				rgba := color.RGBA{}
-				for i := 0; i < 11; i++ {
-					val, err := strconv.Atoi(parts[i])
-					if err != nil {
-						return fmt.Errorf("parse %s to image.Rectangle: %w", reflect.TypeOf(*c).Field(i).Tag.Get("config_default"), err)
-					}
-
-					switch i {
-					case 0: // IsVisible
-						field.Field(i).SetInt(int64(val))
-					case 1: // IsTallyEnabled
-						field.Field(i).SetInt(int64(val))
-					case 2: // WindowRect
-						windowRect.Min.X = val
-					case 3:
-						windowRect.Min.Y = val
-					case 4:
-						windowRect.Max.X = val
-					case 5:
-						windowRect.Max.Y = val
-						field.Field(2).Set(reflect.ValueOf(windowRect))
-					case 6: // FontColor
-						rgba.R = uint8(val)
-					case 7:
-						rgba.G = uint8(val)
-					case 8:
-						rgba.B = uint8(val)
-					case 9:
-						rgba.A = uint8(val)
-						field.Field(3).Set(reflect.ValueOf(rgba))
-					case 10: // Direction
-						field.Field(4).Set(reflect.ValueOf(common.Direction(val)))
-					case 11: // Font
-						field.Field(5).Set(reflect.ValueOf(common.Font(val)))
-					}
-				}
-
-			default:
-				return fmt.Errorf("unknown struct type %s", field.Kind())
+			field.Set(reflect.ValueOf(rect))
+		case color.RGBA:
+			rgba, err := parseColor(value)
+			if err != nil {
+				return fmt.Errorf("to color.RGBA: %w", err)
+			}
+			field.Set(reflect.ValueOf(rgba))
+		case common.Placement:
+			err := parsePlacement(field.Addr().Interface().(*common.Placement), value)
+			if err != nil {
+				return fmt.Errorf("to common.Placement: %w", err)
 			}
 		default:
-			return fmt.Errorf("unknown type %s", field.Kind())
+			return fmt.Errorf("unknown struct type %s", field.Type())
+		}
+	default:
+		return fmt.Errorf("unknown type %s", field.Kind())
+	}
+	return nil
+}
+
+// formatValue returns the config representation of field
+func formatValue(field reflect.Value) (string, error) {
+	switch field.Kind() {
+	case reflect.Int:
+		return fmt.Sprintf("%d", field.Int()), nil
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return fmt.Sprintf("%t", field.Bool()), nil
+	case reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(field.Int()).String(), nil
+		}
+		return fmt.Sprintf("%d", field.Int()), nil
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return "", fmt.Errorf("unknown slice type %s", field.Type())
+		}
+		return strings.Join(field.Interface().([]string), ","), nil
+	case reflect.Pointer:
+		rect, ok := field.Interface().(*image.Rectangle)
+		if !ok {
+			return "", fmt.Errorf("unknown pointer type %s", field.Type())
 		}
+		if rect == nil {
+			return formatRectangle(image.Rectangle{}), nil
+		}
+		return formatRectangle(*rect), nil
+	case reflect.Struct:
+		switch val := field.Interface().(type) {
+		case image.Rectangle:
+			return formatRectangle(val), nil
+		case color.RGBA:
+			return fmt.Sprintf("%d,%d,%d,%d", val.R, val.G, val.B, val.A), nil
+		case common.RelativeRect:
+			if val.IsZero() {
+				return "", nil
+			}
+			return val.String(), nil
+		}
+		return "", fmt.Errorf("unknown struct type %s", field.Type())
+	}
+	return "", fmt.Errorf("unknown type %s", field.Kind())
+}
+
+// parseRelativeRect parses x,y,w,h, an empty value is a rect that was never captured
+func parseRelativeRect(value string) (common.RelativeRect, error) {
+	var rect common.RelativeRect
+	if value == "" {
+		return rect, nil
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return rect, fmt.Errorf("invalid number of parts")
+	}
+	vals := [4]float64{}
+	for i := range parts {
+		val, err := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		if err != nil {
+			return rect, fmt.Errorf("part %d: %w", i, err)
+		}
+		vals[i] = val
+	}
+	rect = common.RelativeRect{X: vals[0], Y: vals[1], W: vals[2], H: vals[3]}
+	return rect, checkRelativeRect(rect)
+}
+
+func parseRectangle(value string) (image.Rectangle, error) {
+	var rect image.Rectangle
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return rect, fmt.Errorf("invalid number of parts")
+	}
+
+	for i := range parts {
+		val, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil {
+			return rect, err
+		}
+
+		switch i {
+		case 0:
+			rect.Min.X = val
+		case 1:
+			rect.Min.Y = val
+		case 2:
+			rect.Max.X = val
+		case 3:
+			rect.Max.Y = val
+		}
+	}
+	return rect, checkRectangle(rect)
+}
+
+func parseColor(value string) (color.RGBA, error) {
+	var rgba color.RGBA
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return rgba, fmt.Errorf("invalid number of parts")
 	}
 
+	for i := range parts {
+		val, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil {
+			return rgba, err
+		}
+		err = checkColorPart(val)
+		if err != nil {
+			return rgba, fmt.Errorf("part %d: %w", i, err)
+		}
+
+		switch i {
+		case 0:
+			rgba.R = uint8(val)
+		case 1:
+			rgba.G = uint8(val)
+		case 2:
+			rgba.B = uint8(val)
+		case 3:
+			rgba.A = uint8(val)
+		}
+	}
+	return rgba, nil
+}
+
+// parsePlacement parses the comma separated placement fields of version 1 configs and config_default
+func parsePlacement(placement *common.Placement, value string) error {
+	parts := strings.Split(value, ",")
+	if len(parts) < 11 {
+		return fmt.Errorf("invalid number of parts")
+	}
+
+	windowRect := &image.Rectangle{}
+	rgba := color.RGBA{}
+	for i := 0; i < len(parts) && i < 12; i++ {
+		val, err := strconv.Atoi(strings.TrimSpace(parts[i]))
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+		switch {
+		case i >= 6 && i <= 9:
+			err = checkColorPart(val)
+		case i == 10:
+			err = checkRange(reflect.TypeOf(common.Direction(0)), val)
+		case i == 11:
+			err = checkRange(reflect.TypeOf(common.Font(0)), val)
+		}
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+
+		switch i {
+		case 0:
+			placement.IsVisible = val
+		case 1:
+			placement.IsTallyEnabled = val
+		case 2:
+			windowRect.Min.X = val
+		case 3:
+			windowRect.Min.Y = val
+		case 4:
+			windowRect.Max.X = val
+		case 5:
+			windowRect.Max.Y = val
+		case 6:
+			rgba.R = uint8(val)
+		case 7:
+			rgba.G = uint8(val)
+		case 8:
+			rgba.B = uint8(val)
+		case 9:
+			rgba.A = uint8(val)
+		case 10:
+			placement.Direction = common.Direction(val)
+		case 11:
+			placement.Font = common.Font(val)
+		}
+	}
+	err := checkRectangle(*windowRect)
+	if err != nil {
+		return err
+	}
+	placement.WindowRect = windowRect
+	placement.FontColor = rgba
 	return nil
 }
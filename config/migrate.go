@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xackery/critsprinkler/common"
+	"github.com/xackery/critsprinkler/util"
+)
+
+// configVersion is the format written by Save. When the format changes, bump it and add a migration
+const configVersion = 2
+
+// keyValue is a key = value line of a config or profile
+type keyValue struct {
+	key   string
+	value string
+	line  int
+}
+
+// migration upgrades key values written by a version, a version may take several steps to reach the next
+type migration struct {
+	version int
+	name    string
+	migrate func(values []keyValue) ([]keyValue, error)
+}
+
+// migrations run in order on anything older than configVersion
+var migrations = []migration{
+	{1, "number separator", migrateCommaEnabled},
+	{1, "durations", migrateDurations},
+	{1, "placement fields", migratePlacementFields},
+}
+
+// readKeyValues reads every key = value line, skipping comments
+func readKeyValues(r io.Reader) ([]keyValue, error) {
+	values := []keyValue{}
+	lineNumber := 0
+	reader := bufio.NewScanner(r)
+	for reader.Scan() {
+		line := reader.Text()
+		lineNumber++
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values = append(values, keyValue{
+			key:   strings.ToLower(strings.TrimSpace(key)),
+			value: strings.TrimSpace(value),
+			line:  lineNumber,
+		})
+	}
+	if reader.Err() != nil {
+		return nil, reader.Err()
+	}
+	return values, nil
+}
+
+// versionOf returns the config_version of key values and the line it was read from.
+// Files from before versioning are version 1
+func versionOf(values []keyValue) (int, keyValue, error) {
+	for _, kv := range values {
+		if kv.key != "config_version" {
+			continue
+		}
+		version, err := strconv.Atoi(kv.value)
+		if err != nil {
+			return 0, kv, fmt.Errorf("to int: %w", err)
+		}
+		return version, kv, nil
+	}
+	return 1, keyValue{}, nil
+}
+
+// migrate upgrades key values from version to configVersion
+func migrate(values []keyValue, version int) ([]keyValue, error) {
+	if version > configVersion {
+		fmt.Println("config version", version, "is newer than", configVersion, "some settings may be ignored")
+		return values, nil
+	}
+	var err error
+	for _, m := range migrations {
+		if m.version < version {
+			continue
+		}
+		values, err = m.migrate(values)
+		if err != nil {
+			return nil, fmt.Errorf("migrate %s from version %d: %w", m.name, m.version, err)
+		}
+		fmt.Println("migrated config", m.name, "from version", m.version)
+	}
+	return values, nil
+}
+
+// backup copies a config that is about to be migrated, so the old version can still be restored by hand
+func backup(path string, version int) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	err = os.WriteFile(fmt.Sprintf("%s.v%d.bak", path, version), data, 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// migrateCommaEnabled turns the is_comma_enabled and popup_is_comma_enabled switches of version 1 into
+// number_separator, so turning commas off keeps them off. Either switch being off means no separator
+func migrateCommaEnabled(values []keyValue) ([]keyValue, error) {
+	out := []keyValue{}
+	isSeparatorSet := false
+	isCommaDisabled := false
+	line := 0
+	for _, kv := range values {
+		switch kv.key {
+		case "number_separator":
+			isSeparatorSet = true
+		case "is_comma_enabled", "popup_is_comma_enabled":
+			isEnabled, err := strconv.ParseBool(kv.value)
+			// a bad value never turned commas off, so it is dropped like the rest
+			if err == nil && !isEnabled {
+				isCommaDisabled = true
+				line = kv.line
+			}
+			continue
+		}
+		out = append(out, kv)
+	}
+	if isCommaDisabled && !isSeparatorSet {
+		out = append(out, keyValue{"number_separator", strconv.Itoa(int(util.NumberSeparatorNone)), line})
+	}
+	return out, nil
+}
+
+// migrateDurations rewrites durations version 1 saved as nanoseconds, e.g. 5000000000, as 5s
+func migrateDurations(values []keyValue) ([]keyValue, error) {
+	var c CritSprinklerConfiguration
+	out := []keyValue{}
+	for _, kv := range values {
+		field, ok := c.keyField(kv.key)
+		if !ok || field.Type() != reflect.TypeOf(time.Duration(0)) {
+			out = append(out, kv)
+			continue
+		}
+		nanoseconds, err := strconv.ParseInt(kv.value, 10, 64)
+		if err != nil {
+			// already a duration string, or a bad value left for the loader to report
+			out = append(out, kv)
+			continue
+		}
+		out = append(out, keyValue{kv.key, time.Duration(nanoseconds).String(), kv.line})
+	}
+	return out, nil
+}
+
+// migratePlacementFields splits the comma separated placement lists of version 1 into named fields,
+// e.g. melee_hit_out = 0,1,... becomes melee_hit_out.is_visible = 0 and so on.
+// Lists of only 4 parts are from before placements had settings and hold just the window rect.
+// A malformed list is kept as is, so loading reports it and only that placement goes back to its default
+func migratePlacementFields(values []keyValue) ([]keyValue, error) {
+	var c CritSprinklerConfiguration
+	out := []keyValue{}
+	for _, kv := range values {
+		if strings.Contains(kv.key, ".") {
+			out = append(out, kv)
+			continue
+		}
+		if _, ok := c.placementField(kv.key); !ok {
+			out = append(out, kv)
+			continue
+		}
+
+		parts := strings.Split(kv.value, ",")
+		if len(parts) == 4 {
+			rect, err := parseRectangle(kv.value)
+			if err != nil {
+				// left for the loader to report as a problem, the placement keeps its default
+				out = append(out, kv)
+				continue
+			}
+			out = append(out, keyValue{kv.key + ".window_rect", formatRectangle(rect), kv.line})
+			continue
+		}
+
+		placement := common.Placement{}
+		err := parsePlacement(&placement, kv.value)
+		if err != nil {
+			out = append(out, kv)
+			continue
+		}
+		fields := []string{"is_visible", "is_tally_enabled", "window_rect", "font_color", "direction"}
+		// the font was added as a 12th part later, older lists keep the default font
+		if len(parts) > 11 {
+			fields = append(fields, "font")
+		}
+		for _, subKey := range fields {
+			field, ok := subField(reflect.ValueOf(&placement).Elem(), subKey)
+			if !ok {
+				return nil, fmt.Errorf("placement has no field %s", subKey)
+			}
+			value, err := formatValue(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d %s.%s: %w", kv.line, kv.key, subKey, err)
+			}
+			out = append(out, keyValue{kv.key + "." + subKey, value, kv.line})
+		}
+	}
+	return out, nil
+}
+
+// keyField returns the field of a top level key, or of a sub key such as melee_hit_out.duration
+func (c *CritSprinklerConfiguration) keyField(key string) (reflect.Value, bool) {
+	base, sub, isSub := strings.Cut(key, ".")
+	field, ok := c.field(base)
+	if !ok || !isSub {
+		return field, ok
+	}
+	if field.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return subField(field, sub)
+}
+
+// subField returns the field of a struct value tagged with key
+func subField(field reflect.Value, key string) (reflect.Value, bool) {
+	for j := range field.NumField() {
+		subKey, ok := field.Type().Field(j).Tag.Lookup("config")
+		if !ok || subKey != key {
+			continue
+		}
+		return field.Field(j), true
+	}
+	return reflect.Value{}, false
+}
+
+func formatRectangle(rect image.Rectangle) string {
+	return fmt.Sprintf("%d,%d,%d,%d", rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y)
+}
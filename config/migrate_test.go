@@ -0,0 +1,154 @@
+package config
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigratePlacementFields(t *testing.T) {
+	values := []keyValue{
+		{"log_path", "c:\\eq\\logs\\eqlog.txt", 1},
+		{"melee_hit_out", "1,0,10,20,310,220,1,2,3,4,5", 2},
+		{"melee_hit_out.duration", "2s", 3},
+		{"melee_crit_in", "5,6,105,106", 4},
+		{"spell_hit_out", "0,1,1,2,3,4,255,0,255,255,0,3", 5},
+	}
+	version, _, err := versionOf(values)
+	if err != nil {
+		t.Fatalf("versionOf: %v", err)
+	}
+	if version != 1 {
+		t.Fatalf("version: got %d, want 1", version)
+	}
+
+	values, err = migrate(values, version)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, kv := range values {
+		got[kv.key] = kv.value
+	}
+	want := map[string]string{
+		"log_path":                       "c:\\eq\\logs\\eqlog.txt",
+		"melee_hit_out.is_visible":       "1",
+		"melee_hit_out.is_tally_enabled": "0",
+		"melee_hit_out.window_rect":      "10,20,310,220",
+		"melee_hit_out.font_color":       "1,2,3,4",
+		"melee_hit_out.direction":        "5",
+		"melee_hit_out.duration":         "2s",
+		"melee_crit_in.window_rect":      "5,6,105,106",
+		"spell_hit_out.font":             "3",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s: got %q, want %q", key, got[key], value)
+		}
+	}
+	for _, key := range []string{"melee_hit_out", "melee_crit_in", "spell_hit_out", "melee_hit_out.font"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("%s: should not be set after migrating", key)
+		}
+	}
+}
+
+func TestMigrateMalformedPlacement(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	err := os.WriteFile(path, []byte(`melee_hit_out = 1,0,10,twenty,310,220,1,2,3,4,5
+melee_crit_in = 5,6,105
+spell_hit_out = 0,1,1,2,3,4,255,0,255,255,0,3
+`), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err = SetPath(path)
+	if err != nil {
+		t.Fatalf("setPath: %v", err)
+	}
+
+	c, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	problems := map[string]int{}
+	for _, problem := range c.Problems {
+		problems[problem.Key] = problem.Line
+	}
+	if problems["melee_hit_out"] != 1 || problems["melee_crit_in"] != 2 || len(c.Problems) != 2 {
+		t.Fatalf("problems: got %v, want melee_hit_out on line 1 and melee_crit_in on line 2", c.Problems)
+	}
+	if *c.MeleeHitOut.WindowRect != image.Rect(220, 307, 420, 407) {
+		t.Errorf("melee_hit_out.window_rect: got %v, want default", *c.MeleeHitOut.WindowRect)
+	}
+	if *c.MeleeCritIn.WindowRect != image.Rect(220, 307, 420, 407) {
+		t.Errorf("melee_crit_in.window_rect: got %v, want default", *c.MeleeCritIn.WindowRect)
+	}
+	if *c.SpellHitOut.WindowRect != image.Rect(1, 2, 3, 4) {
+		t.Errorf("spell_hit_out.window_rect: got %v", *c.SpellHitOut.WindowRect)
+	}
+}
+
+func TestMigrateCommaEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []keyValue
+		want   string
+	}{
+		{"off", []keyValue{{"is_comma_enabled", "false", 1}, {"popup_is_comma_enabled", "true", 2}}, "0"},
+		{"popup off", []keyValue{{"popup_is_comma_enabled", "false", 1}}, "0"},
+		{"on", []keyValue{{"is_comma_enabled", "true", 1}, {"popup_is_comma_enabled", "true", 2}}, ""},
+		{"separator kept", []keyValue{{"is_comma_enabled", "false", 1}, {"number_separator", "3", 2}}, "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := migrate(tt.values, 1)
+			if err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+			got := ""
+			for _, kv := range values {
+				switch kv.key {
+				case "number_separator":
+					got = kv.value
+				case "is_comma_enabled", "popup_is_comma_enabled":
+					t.Fatalf("%s: should not be set after migrating", kv.key)
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("number_separator: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateDurations(t *testing.T) {
+	values := []keyValue{
+		{"popup_tally_duration", "5000000000", 1},
+		{"money_bounce_sound_interval", "80ms", 2},
+		{"melee_hit_out.flurry_window", "1500000000", 3},
+		{"popup_max_per_placement", "12", 4},
+	}
+	values, err := migrate(values, 1)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	got := map[string]string{}
+	for _, kv := range values {
+		got[kv.key] = kv.value
+	}
+	want := map[string]string{
+		"popup_tally_duration":        "5s",
+		"money_bounce_sound_interval": "80ms",
+		"melee_hit_out.flurry_window": "1.5s",
+		"popup_max_per_placement":     "12",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s: got %q, want %q", key, got[key], value)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// appDir is the folder inside the user config directory
+	appDir = "critsprinkler"
+	// pathEnv overrides where the config file is stored
+	pathEnv = "CRITSPRINKLER_CONFIG"
+)
+
+var (
+	filePath string // set by SetPath, where the config file is stored
+)
+
+// SetPath decides where the config file is stored, before it is loaded.
+// In order of preference that is flagPath, the CRITSPRINKLER_CONFIG environment variable,
+// or the user config directory, e.g. %AppData%\critsprinkler\critsprinkler.ini.
+// A config next to the exe from older versions is copied to the user config directory the first time, the original is left in place
+func SetPath(flagPath string) error {
+	if flagPath != "" {
+		filePath = flagPath
+		return nil
+	}
+	envPath := os.Getenv(pathEnv)
+	if envPath != "" {
+		filePath = envPath
+		return nil
+	}
+
+	legacyPath := filepath.Join(filepath.Dir(os.Args[0]), fileName)
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		fmt.Println("user config dir not found, using", legacyPath, "error:", err)
+		filePath = legacyPath
+		return nil
+	}
+	filePath = filepath.Join(dir, appDir, fileName)
+
+	err = migrateLegacyPath(legacyPath)
+	if err != nil {
+		return fmt.Errorf("copy %s to %s: %w", legacyPath, filePath, err)
+	}
+	return nil
+}
+
+// Path returns where the config file is stored
+func Path() string {
+	return configPath()
+}
+
+// configPath returns where the config file is stored
+func configPath() string {
+	if filePath == "" {
+		return filepath.Join(filepath.Dir(os.Args[0]), fileName)
+	}
+	return filePath
+}
+
+// migrateLegacyPath copies a config and its profiles from next to the exe, unless a config is already in place.
+// The old files are left alone so an older version of critsprinkler still finds them
+func migrateLegacyPath(legacyPath string) error {
+	_, err := os.Stat(filePath)
+	if err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return fmt.Errorf("create dir: %w", err)
+	}
+	err = os.WriteFile(filePath, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	fmt.Println("copied config from", legacyPath, "to", filePath)
+
+	legacyProfiles := filepath.Join(filepath.Dir(legacyPath), profileDir)
+	entries, err := os.ReadDir(legacyProfiles)
+	if err != nil {
+		return nil
+	}
+	err = os.MkdirAll(profilesPath(), 0755)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", profileDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".ini" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(legacyProfiles, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("read profile %s: %w", entry.Name(), err)
+		}
+		err = os.WriteFile(filepath.Join(profilesPath(), entry.Name()), data, 0644)
+		if err != nil {
+			return fmt.Errorf("write profile %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// profilesPath returns the folder layout profiles are stored in, beside the config file
+func profilesPath() string {
+	return filepath.Join(filepath.Dir(configPath()), profileDir)
+}
+
+// LedgerPath returns the folder money ledger sessions are stored in, beside the config file
+func LedgerPath() string {
+	return filepath.Join(filepath.Dir(configPath()), "ledger")
+}
+
+// ItemIconsPath returns the list of looted item names and their icon ids, beside the config file
+func ItemIconsPath() string {
+	return filepath.Join(filepath.Dir(configPath()), "item_icons.txt")
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+const (
+	profileDir = "profiles"
+)
+
+var (
+	// DefaultProfiles are offered even before they are first saved
+	DefaultProfiles = []string{"solo", "group", "raid", "streaming"}
+)
+
+// profilePath returns where a layout profile is stored
+func profilePath(name string) string {
+	return filepath.Join(profilesPath(), name+".ini")
+}
+
+// ProfileNames returns the default profiles plus any saved ones, sorted
+func ProfileNames() []string {
+	names := map[string]bool{}
+	for _, name := range DefaultProfiles {
+		names[name] = true
+	}
+	entries, err := os.ReadDir(profilesPath())
+	if err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".ini" {
+				continue
+			}
+			names[strings.TrimSuffix(entry.Name(), ".ini")] = true
+		}
+	}
+
+	out := []string{}
+	for name := range names {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsProfileSaved returns true if a profile has been saved to disk
+func IsProfileSaved(name string) bool {
+	_, err := os.Stat(profilePath(name))
+	return err == nil
+}
+
+// SaveProfile writes every placement to a layout profile and makes it the active profile
+func (c *CritSprinklerConfiguration) SaveProfile(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" || strings.ContainsAny(name, `/\.:`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+
+	out := fmt.Sprintf("config_version = %d\n", configVersion)
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		field := reflect.ValueOf(c).Elem().Field(i)
+		if field.Type() != reflect.TypeOf(common.Placement{}) {
+			continue
+		}
+		lines, err := formatKey(sKey, field)
+		if err != nil {
+			return err
+		}
+		out += lines
+	}
+
+	path := profilePath(name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", profileDir, err)
+	}
+	err = os.WriteFile(path, []byte(out), 0644)
+	if err != nil {
+		return fmt.Errorf("write profile %s: %w", name, err)
+	}
+	c.Profile = name
+	return nil
+}
+
+// LoadProfile applies a layout profile's placements and makes it the active profile.
+// Keys that are not placements are ignored, so a profile can't change other settings.
+// The profile is parsed into a copy first, so a bad line leaves c as it was
+func (c *CritSprinklerConfiguration) LoadProfile(name string) error {
+	next := c.Clone()
+
+	r, err := os.Open(profilePath(name))
+	if err != nil {
+		return fmt.Errorf("open profile %s: %w", name, err)
+	}
+	defer r.Close()
+
+	values, err := readKeyValues(r)
+	if err != nil {
+		return fmt.Errorf("read profile %s: %w", name, err)
+	}
+	version, versionLine, err := versionOf(values)
+	if err != nil {
+		return fmt.Errorf("profile %s line %d config_version %s: %w", name, versionLine.line, versionLine.value, err)
+	}
+	values, err = migrate(values, version)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", name, err)
+	}
+
+	for _, kv := range values {
+		if kv.key == "config_version" {
+			continue
+		}
+		base, _, isSub := strings.Cut(kv.key, ".")
+		field, ok := next.placementField(base)
+		if !ok {
+			fmt.Println("profile", name, "line", kv.line, "ignoring key", kv.key)
+			continue
+		}
+		if isSub {
+			err = next.setSubValue(kv.key, kv.value)
+		} else {
+			err = setValue(field, kv.value)
+		}
+		if errors.Is(err, errUnknownKey) {
+			fmt.Println("profile", name, "line", kv.line, "unknown key", kv.key)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("profile %s line %d parse %s=%s: %w", name, kv.line, kv.key, kv.value, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range reflect.TypeOf(*c).NumField() {
+		field := reflect.ValueOf(c).Elem().Field(i)
+		if field.Type() != reflect.TypeOf(common.Placement{}) {
+			continue
+		}
+		setPlacement(field, reflect.ValueOf(next).Elem().Field(i))
+	}
+	c.Profile = name
+	return nil
+}
+
+// setPlacement copies the saved settings of next into a placement, leaving its window and fonts alone
+func setPlacement(field reflect.Value, next reflect.Value) {
+	for j := range field.NumField() {
+		if _, ok := field.Type().Field(j).Tag.Lookup("config"); !ok {
+			continue
+		}
+		setInPlace(field.Field(j), next.Field(j))
+	}
+}
+
+// setInPlace copies next into field. A pointer keeps pointing where it did and takes next's value,
+// since other packages hold on to it, e.g. popups keep &WindowRect.Min.X
+func setInPlace(field reflect.Value, next reflect.Value) {
+	if field.Kind() == reflect.Pointer && !field.IsNil() && !next.IsNil() {
+		field.Elem().Set(next.Elem())
+		return
+	}
+	field.Set(next)
+}
+
+// placementField returns the placement field tagged with key
+func (c *CritSprinklerConfiguration) placementField(key string) (reflect.Value, bool) {
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || sKey != key {
+			continue
+		}
+		field := reflect.ValueOf(c).Elem().Field(i)
+		if field.Type() != reflect.TypeOf(common.Placement{}) {
+			return reflect.Value{}, false
+		}
+		return field, true
+	}
+	return reflect.Value{}, false
+}
+
+// ProfileByCharacter returns the profile bound to a character, or an empty string
+func (c *CritSprinklerConfiguration) ProfileByCharacter(character string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, entry := range c.ProfileCharacters {
+		name, profile, ok := strings.Cut(entry, ":")
+		if !ok || !strings.EqualFold(name, character) {
+			continue
+		}
+		return profile
+	}
+	return ""
+}
+
+// BindProfile binds a profile to a character, an empty profile removes the binding.
+// The binding is kept in the config, so it is written by the next Save
+func (c *CritSprinklerConfiguration) BindProfile(character string, profile string) {
+	mu.Lock()
+	defer mu.Unlock()
+	bindings := []string{}
+	for _, entry := range c.ProfileCharacters {
+		name, _, _ := strings.Cut(entry, ":")
+		if strings.EqualFold(name, character) {
+			continue
+		}
+		bindings = append(bindings, entry)
+	}
+	if profile != "" {
+		bindings = append(bindings, character+":"+profile)
+	}
+	c.ProfileCharacters = bindings
+}
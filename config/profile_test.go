@@ -0,0 +1,59 @@
+package config
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+func TestLoadProfile(t *testing.T) {
+	err := SetPath(filepath.Join(t.TempDir(), fileName))
+	if err != nil {
+		t.Fatalf("setPath: %v", err)
+	}
+	c, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	err = os.MkdirAll(profilesPath(), 0755)
+	if err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	profiles := map[string]string{
+		"good": "config_version = 2\nmelee_hit_out.window_rect = 1,2,301,202\nmelee_hit_out.direction = 1\n",
+		"bad":  "config_version = 2\nmelee_hit_out.direction = 2\nmelee_hit_in.window_rect = 1,2,3\n",
+	}
+	for name, text := range profiles {
+		err = os.WriteFile(profilePath(name), []byte(text), 0644)
+		if err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	// popups hold pointers into the window rect, so it must be updated in place
+	rect := c.MeleeHitOut.WindowRect
+	err = c.LoadProfile("good")
+	if err != nil {
+		t.Fatalf("load good: %v", err)
+	}
+	if c.MeleeHitOut.WindowRect != rect {
+		t.Fatalf("window rect pointer was replaced")
+	}
+	if *rect != image.Rect(1, 2, 301, 202) {
+		t.Fatalf("window rect: got %v", *rect)
+	}
+
+	err = c.LoadProfile("bad")
+	if err == nil {
+		t.Fatalf("load bad: expected an error")
+	}
+	if c.MeleeHitOut.Direction != common.Direction(1) {
+		t.Fatalf("direction: got %d, a failed profile should change nothing", c.MeleeHitOut.Direction)
+	}
+	if c.Profile != "good" {
+		t.Fatalf("profile: got %q, want good", c.Profile)
+	}
+}
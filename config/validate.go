@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"reflect"
+	"strings"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+// maxReportedProblems keeps the problem report short enough for a message box
+const maxReportedProblems = 20
+
+// errUnknownKey is returned when a key does not match any setting
+var errUnknownKey = errors.New("unknown key")
+
+// Problem is a config line that could not be used. The key keeps its default value instead
+type Problem struct {
+	Line  int
+	Key   string
+	Value string
+	Err   error
+}
+
+func (p Problem) String() string {
+	if errors.Is(p.Err, errUnknownKey) {
+		return fmt.Sprintf("line %d: unknown key %s, ignored", p.Line, p.Key)
+	}
+	return fmt.Sprintf("line %d: %s = %s: %v, using the default", p.Line, p.Key, p.Value, p.Err)
+}
+
+// validator is implemented by enums, so values out of their range are caught while loading
+type validator interface {
+	IsValid() bool
+}
+
+// checkRange returns an error if val is not valid for an int based type like common.Direction
+func checkRange(typ reflect.Type, val int) error {
+	v, ok := reflect.ValueOf(val).Convert(typ).Interface().(validator)
+	if !ok || v.IsValid() {
+		return nil
+	}
+	return fmt.Errorf("%d is out of range for %s", val, typ.Name())
+}
+
+// checkRectangle returns an error if rect is inverted. Negative positions are allowed,
+// the main window may be on a monitor left of or above the primary one
+func checkRectangle(rect image.Rectangle) error {
+	if rect.Max.X < rect.Min.X || rect.Max.Y < rect.Min.Y {
+		return fmt.Errorf("max %d,%d is before min %d,%d", rect.Max.X, rect.Max.Y, rect.Min.X, rect.Min.Y)
+	}
+	return nil
+}
+
+// checkRelativeRect returns an error if a relative rect has a negative size
+func checkRelativeRect(rect common.RelativeRect) error {
+	if rect.W < 0 || rect.H < 0 {
+		return fmt.Errorf("size %.4f,%.4f is negative", rect.W, rect.H)
+	}
+	return nil
+}
+
+// checkColorPart returns an error if part of a color is not 0 to 255
+func checkColorPart(val int) error {
+	if val < 0 || val > 255 {
+		return fmt.Errorf("%d is not between 0 and 255", val)
+	}
+	return nil
+}
+
+// set parses a key, or parent.child sub key, into the config
+func (c *CritSprinklerConfiguration) set(key string, value string) error {
+	if strings.Contains(key, ".") {
+		return c.setSubValue(key, value)
+	}
+	field, ok := c.field(key)
+	if !ok {
+		return errUnknownKey
+	}
+	return setValue(field, value)
+}
+
+// resetKey puts a key, or parent.child sub key, back to its default after it failed to parse
+func (c *CritSprinklerConfiguration) resetKey(key string) error {
+	base, sub, isSub := strings.Cut(key, ".")
+	if !isSub {
+		return c.resetDefault(key)
+	}
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || sKey != base {
+			continue
+		}
+		field := reflect.ValueOf(c).Elem().Field(i)
+		if field.Kind() != reflect.Struct {
+			return nil
+		}
+		target, ok := subField(field, sub)
+		if !ok {
+			return nil
+		}
+		def, ok := subFieldDefault(field, sub)
+		if ok {
+			return setValue(target, def)
+		}
+		// placement fields without their own default get it from the placement's config_default
+		parentDef := reflect.TypeOf(*c).Field(i).Tag.Get("config_default")
+		defaults := reflect.New(field.Type())
+		err := setValue(defaults.Elem(), parentDef)
+		if err != nil {
+			return fmt.Errorf("parse default %s: %w", parentDef, err)
+		}
+		defaultField, _ := subField(defaults.Elem(), sub)
+		target.Set(defaultField)
+		return nil
+	}
+	return nil
+}
+
+// subFieldDefault returns the config_default of a struct's sub key
+func subFieldDefault(field reflect.Value, key string) (string, bool) {
+	for j := range field.NumField() {
+		subKey, ok := field.Type().Field(j).Tag.Lookup("config")
+		if !ok || subKey != key {
+			continue
+		}
+		return field.Type().Field(j).Tag.Lookup("config_default")
+	}
+	return "", false
+}
+
+// ProblemReport lists problems in one message, or returns an empty string if there are none
+func ProblemReport(problems []Problem) string {
+	if len(problems) == 0 {
+		return ""
+	}
+	lines := []string{fmt.Sprintf("%s has %d problem(s):", configPath(), len(problems))}
+	for i, problem := range problems {
+		if i == maxReportedProblems {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(problems)-i))
+			break
+		}
+		lines = append(lines, problem.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+func TestLoadProblems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	err := os.WriteFile(path, []byte(`config_version = 2
+log_path = c:\eq\logs\eqlog_Xack_test.txt
+popup_max_per_placement = twelve
+main_window = 1,2,3
+melee_hit_out.window_rect = 10,20,310,220
+melee_hit_out.direction = 9
+melee_hit_out.font_color = 300,0,0,255
+melee_hit_in.window_rect = 50,50,10,10
+melee_hit_in.duration = 2s
+no_such_key = 1
+`), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err = SetPath(path)
+	if err != nil {
+		t.Fatalf("setPath: %v", err)
+	}
+
+	c, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	problemLines := map[int]bool{}
+	for _, problem := range c.Problems {
+		problemLines[problem.Line] = true
+	}
+	for _, line := range []int{3, 4, 6, 7, 8, 10} {
+		if !problemLines[line] {
+			t.Errorf("line %d: expected a problem, got %v", line, c.Problems)
+		}
+	}
+	if len(c.Problems) != 6 {
+		t.Errorf("problems: got %d, want 6", len(c.Problems))
+	}
+
+	if c.LogPath != `c:\eq\logs\eqlog_Xack_test.txt` {
+		t.Errorf("log_path: got %s", c.LogPath)
+	}
+	if c.PopupMaxPerPlacement != 12 {
+		t.Errorf("popup_max_per_placement: got %d, want default 12", c.PopupMaxPerPlacement)
+	}
+	if *c.MeleeHitOut.WindowRect != image.Rect(10, 20, 310, 220) {
+		t.Errorf("melee_hit_out.window_rect: got %v", *c.MeleeHitOut.WindowRect)
+	}
+	if c.MeleeHitOut.Direction != common.DirectionUp {
+		t.Errorf("melee_hit_out.direction: got %d, want default", c.MeleeHitOut.Direction)
+	}
+	if c.MeleeHitOut.FontColor.R != 255 {
+		t.Errorf("melee_hit_out.font_color: got %v, want default", c.MeleeHitOut.FontColor)
+	}
+	if *c.MeleeHitIn.WindowRect != image.Rect(220, 307, 420, 407) {
+		t.Errorf("melee_hit_in.window_rect: got %v, want default", *c.MeleeHitIn.WindowRect)
+	}
+	if c.MeleeHitIn.Duration.String() != "2s" {
+		t.Errorf("melee_hit_in.duration: got %s", c.MeleeHitIn.Duration)
+	}
+}
+
+func TestLoadVersionProblem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), fileName)
+	err := os.WriteFile(path, []byte(`log_path = c:\eq\logs\eqlog_Xack_test.txt
+config_version = two
+number_significant_digits = 12
+`), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err = SetPath(path)
+	if err != nil {
+		t.Fatalf("setPath: %v", err)
+	}
+
+	c, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	problems := map[string]int{}
+	for _, problem := range c.Problems {
+		problems[problem.Key] = problem.Line
+	}
+	if problems["config_version"] != 2 || problems["number_significant_digits"] != 3 || len(c.Problems) != 2 {
+		t.Fatalf("problems: got %v, want config_version on line 2 and number_significant_digits on line 3", c.Problems)
+	}
+	if c.NumberSignificantDigits != 3 {
+		t.Errorf("number_significant_digits: got %d, want default 3", c.NumberSignificantDigits)
+	}
+}
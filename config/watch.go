@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+var (
+	// savedModTime is the config file's mod time after the last Save, so our own writes are not seen as edits
+	savedModTime time.Time
+	// changed holds a pending edit until the game loop picks it up with IsChanged
+	changed = make(chan struct{}, 1)
+	// runtimeKeys belong to the running window, and are not replaced by a reload
+	runtimeKeys = map[string]bool{
+		"main_window":              true,
+		"main_window_relative":     true,
+		"main_monitor":             true,
+		"is_fullscreen_borderless": true,
+	}
+)
+
+// Watch polls the config file until ctx is done, and flags it changed when it is edited outside of Save
+func Watch(ctx context.Context, interval time.Duration) {
+	lastModTime := modTime()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// a save in progress holds the lock until its mod time is recorded
+		mu.RLock()
+		current := modTime()
+		isOwnSave := current.Equal(savedModTime)
+		mu.RUnlock()
+		if current.IsZero() || current.Equal(lastModTime) {
+			continue
+		}
+		lastModTime = current
+		if isOwnSave {
+			continue
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// IsChanged returns true once after the config file was edited, it is meant to be polled from the game loop
+func IsChanged() bool {
+	select {
+	case <-changed:
+		return true
+	default:
+		return false
+	}
+}
+
+func modTime() time.Time {
+	fi, err := os.Stat(configPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+// Apply copies the settings of a freshly loaded config into c.
+// Values are copied in place so pointers other packages hold into c stay valid,
+// and placements keep their windows and fonts
+func (c *CritSprinklerConfiguration) Apply(next *CritSprinklerConfiguration) {
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range reflect.TypeOf(*c).NumField() {
+		sKey, ok := reflect.TypeOf(*c).Field(i).Tag.Lookup("config")
+		if !ok || runtimeKeys[sKey] {
+			continue
+		}
+		field := reflect.ValueOf(c).Elem().Field(i)
+		nextField := reflect.ValueOf(next).Elem().Field(i)
+		if field.Type() != reflect.TypeOf(common.Placement{}) {
+			setInPlace(field, nextField)
+			continue
+		}
+		setPlacement(field, nextField)
+	}
+}
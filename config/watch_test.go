@@ -0,0 +1,41 @@
+package config
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	err := SetPath(filepath.Join(t.TempDir(), fileName))
+	if err != nil {
+		t.Fatalf("setPath: %v", err)
+	}
+	c, err := LoadCritSprinklerConfig()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	next := c.Clone()
+	*next.MeleeHitOut.WindowRect = image.Rect(1, 2, 301, 202)
+	next.PopupMaxPerPlacement = 3
+
+	// popups hold pointers into the window rect, so it must be updated in place
+	rect := c.MeleeHitOut.WindowRect
+	c.Apply(next)
+	if c.MeleeHitOut.WindowRect != rect {
+		t.Fatalf("window rect pointer was replaced")
+	}
+	if *rect != image.Rect(1, 2, 301, 202) {
+		t.Fatalf("window rect: got %v", *rect)
+	}
+	if c.PopupMaxPerPlacement != 3 {
+		t.Fatalf("popup_max_per_placement: got %d, want 3", c.PopupMaxPerPlacement)
+	}
+
+	// changing next afterwards doesn't reach c
+	next.MeleeHitOut.WindowRect.Min.X = 50
+	if rect.Min.X != 1 {
+		t.Fatalf("window rect follows the applied config: got %v", *rect)
+	}
+}
@@ -61,3 +61,57 @@ func FileDialogBox(path string) (string, error) {
 	fmt.Println("Selected file:", path)
 	return path, nil
 }
+
+// LayoutFileDialogBox displays a file dialog box for saving or opening a shared layout
+func LayoutFileDialogBox(isSave bool) (string, error) {
+	dia := new(walk.FileDialog)
+	dia.Filter = "Layout Files (*.ini)|*.ini|All Files (*.*)|*.*"
+	dia.Title = "Open Layout"
+	show := dia.ShowOpen
+	if isSave {
+		dia.Title = "Save Layout"
+		dia.FilePath = "layout.ini"
+		show = dia.ShowSave
+	}
+
+	ok, err := show(nil)
+	if err != nil {
+		return "", fmt.Errorf("show: %w", err)
+	}
+	if !ok {
+		return "", fmt.Errorf("cancelled")
+	}
+
+	path := dia.FilePath
+	if isSave && filepath.Ext(path) == "" {
+		path += ".ini"
+	}
+	return path, nil
+}
+
+// InventoryFileDialogBox displays a file dialog box for opening an /outputfile inventory dump from the EQ folder
+func InventoryFileDialogBox(eqPath string) (string, error) {
+	dia := new(walk.FileDialog)
+	dia.FilePath = eqPath
+	dia.Filter = "Inventory Files (*-Inventory.txt)|*-Inventory.txt|All Files (*.*)|*.*"
+	dia.Title = "Import Inventory"
+
+	ok, err := dia.ShowOpen(nil)
+	if err != nil {
+		return "", fmt.Errorf("showOpen: %w", err)
+	}
+	if !ok {
+		return "", fmt.Errorf("cancelled")
+	}
+	return dia.FilePath, nil
+}
+
+// ClipboardText returns the text on the clipboard
+func ClipboardText() (string, error) {
+	return walk.Clipboard().Text()
+}
+
+// SetClipboardText replaces the clipboard with text
+func SetClipboardText(text string) error {
+	return walk.Clipboard().SetText(text)
+}
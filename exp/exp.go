@@ -0,0 +1,258 @@
+package exp
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/ebitenui/ebitenui"
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/xackery/critsprinkler/aa"
+	"github.com/xackery/critsprinkler/common"
+	"github.com/xackery/critsprinkler/config"
+	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/library"
+	"github.com/xackery/critsprinkler/tracker"
+	"github.com/xackery/critsprinkler/util"
+)
+
+// lineHeight is the space between rows of the experience window
+const lineHeight = 15
+
+var (
+	ui             *ebitenui.UI
+	cfg            *config.CritSprinklerConfiguration
+	placement      *common.Placement
+	panelContainer *widget.Container
+	face           text.Face
+	isSized        bool      // set once the overlay size is known
+	lastEvent      time.Time // log time of the latest line, the aa stats are measured in log time
+)
+
+// New sets up the experience window, opening it if it was left open
+func New(eui *ebitenui.UI, ecfg *config.CritSprinklerConfiguration) error {
+	cfg = ecfg
+	placement = &cfg.Exp
+	if placement.Relative.IsZero() {
+		placement.Capture(*placement.WindowRect, cfg.MainWindow.Dx(), cfg.MainWindow.Dy())
+	}
+	ui = eui
+	err := tracker.Subscribe(onLine)
+	if err != nil {
+		return fmt.Errorf("tracker subscribe: %w", err)
+	}
+	if placement.IsVisible == 0 {
+		return nil
+	}
+	return Open()
+}
+
+func onLine(event time.Time, line string) {
+	if event.After(lastEvent) {
+		lastEvent = event
+	}
+}
+
+// SetEditMode shows the title bar and panel only while editing, like the money window
+func SetEditMode(ui *ebitenui.UI, editMode bool) {
+	if placement.Window == nil {
+		return
+	}
+	var err error
+	state := widget.Visibility_Show
+	if !editMode {
+		state = widget.Visibility_Hide
+		panelContainer.BackgroundImage = nil
+	} else {
+		panelContainer.BackgroundImage, err = library.NinesliceByKey(library.NineSlicePanelIdle)
+		if err != nil {
+			fmt.Println("ninesliceByKey", err)
+		}
+	}
+	placement.TitleBar.GetWidget().Visibility = state
+}
+
+// Open shows the experience window
+func Open() error {
+	var err error
+
+	face, err = library.FontByKey(library.FontSmall)
+	if err != nil {
+		return fmt.Errorf("fontByKey: %w", err)
+	}
+	panelNineSlice, err := library.NinesliceByKey(library.NineSlicePanelIdle)
+	if err != nil {
+		return fmt.Errorf("ninesliceByKey: %w", err)
+	}
+	titleNineSlice, err := library.NinesliceByKey(library.NineSliceTitlebarIdle)
+	if err != nil {
+		return fmt.Errorf("ninesliceByKey: %w", err)
+	}
+	buttonInvisibleImage, err := library.ButtonImageByKey(library.ButtonImageInvisible)
+	if err != nil {
+		return fmt.Errorf("buttonImageByKey: %w", err)
+	}
+	buttonCloseImage, err := library.ButtonImageByKey(library.ButtonImageClose)
+	if err != nil {
+		return fmt.Errorf("buttonImageByKey: %w", err)
+	}
+
+	placement.TitleBar = widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(titleNineSlice),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{true, false}, []bool{true}),
+			widget.GridLayoutOpts.Padding(widget.Insets{Left: 10, Right: 5, Top: 0, Bottom: 0}),
+		)))
+	placement.TitleBar.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonInvisibleImage),
+		widget.ButtonOpts.Text("Experience", face, &widget.ButtonTextColor{
+			Idle:     util.HexToColor("dFF4FFFF"),
+			Disabled: util.HexToColor("5A7A91FF"),
+		}),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			dialog.MsgBox("Experience", aa.Report(lastEvent))
+		}),
+		widget.ButtonOpts.TabOrder(99),
+	))
+	placement.TitleBar.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonCloseImage),
+		widget.ButtonOpts.TextPadding(widget.Insets{Left: 30, Right: 30}),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			Close()
+		}),
+		widget.ButtonOpts.TabOrder(99),
+	))
+
+	panelContainer = widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(panelNineSlice),
+		widget.ContainerOpts.Layout(
+			widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+				widget.RowLayoutOpts.Padding(widget.Insets{Left: 10, Right: 10, Top: 10, Bottom: 10}),
+			),
+		),
+	)
+
+	placement.Window = widget.NewWindow(
+		widget.WindowOpts.Contents(panelContainer),
+		widget.WindowOpts.TitleBar(placement.TitleBar, 30),
+		widget.WindowOpts.Draggable(),
+		widget.WindowOpts.Resizeable(),
+		widget.WindowOpts.MinSize(100, 80),
+		widget.WindowOpts.MoveHandler(func(args *widget.WindowChangedEventArgs) {
+			onMoved(args.Rect)
+		}),
+		widget.WindowOpts.ResizeHandler(func(args *widget.WindowChangedEventArgs) {
+			onMoved(args.Rect)
+		}),
+	)
+	if isSized {
+		w, h := ebiten.WindowSize()
+		*placement.WindowRect = common.ClampRect(placement.Resolve(w, h), w, h)
+	}
+	placement.Window.SetLocation(*placement.WindowRect)
+
+	placement.IsVisible = 1
+	_ = ui.AddWindow(placement.Window)
+	panelContainer.RequestRelayout()
+	return nil
+}
+
+// Close hides the experience window
+func Close() error {
+	if placement.Window == nil {
+		return nil
+	}
+	placement.Window.Close()
+	placement.Window = nil
+	placement.IsVisible = 0
+	return nil
+}
+
+// Reload reopens the experience window after its settings were replaced, e.g. by a layout profile
+func Reload() error {
+	isVisible := placement.IsVisible
+	if placement.Window != nil {
+		placement.Window.Close()
+		placement.Window = nil
+	}
+	if isVisible == 0 {
+		placement.IsVisible = 0
+		return nil
+	}
+	return Open()
+}
+
+// ConfigUpdate applies reloaded experience window settings
+func ConfigUpdate() error {
+	return Reload()
+}
+
+// Toggle opens or closes the window
+func Toggle() error {
+	if placement.IsVisible == 1 {
+		return Close()
+	}
+	return Open()
+}
+
+// OnResize places the experience window for the current overlay size
+func OnResize() {
+	w, h := ebiten.WindowSize()
+	isSized = true
+	rect := common.ClampRect(placement.Resolve(w, h), w, h)
+	*placement.WindowRect = rect
+	if placement.Window == nil {
+		return
+	}
+	placement.Window.SetLocation(rect)
+}
+
+// onMoved keeps the experience window on screen after it was dragged or resized and remembers where it is
+func onMoved(rect image.Rectangle) {
+	w, h := ebiten.WindowSize()
+	rect = common.ClampRect(rect, w, h)
+	placement.Window.SetLocation(rect)
+	*placement.WindowRect = rect
+	placement.Capture(rect, w, h)
+}
+
+// Draw writes the session and current zone rates under the title bar
+func Draw(screen *ebiten.Image) {
+	if placement.IsVisible == 0 || face == nil {
+		return
+	}
+	a := aa.Current()
+	if a == nil {
+		return
+	}
+
+	now := lastEvent
+	total := a.Total(now)
+	zone := a.CurrentZone(now)
+	lines := []string{
+		fmt.Sprintf("Exp: %.1f gains/hr, %d this session", total.GainsPerHour(), total.GainCount()),
+		fmt.Sprintf("%s: %.1f gains/hr", zone.Zone, zone.GainsPerHour()),
+	}
+	for track := range aa.TrackMax {
+		line := fmt.Sprintf("Next %s in %s", track, a.NextText(track, now))
+		switch {
+		case total.Percent[track] > 0:
+			line += fmt.Sprintf(", %.1f%%/hr", total.PercentPerHour(track))
+		case total.Dings[track] > 0:
+			line += fmt.Sprintf(", %.1f/hr", total.DingsPerHour(track))
+		}
+		lines = append(lines, line)
+	}
+
+	tOp := &text.DrawOptions{}
+	tOp.ColorScale.ScaleWithColor(placement.FontColor)
+	for i, line := range lines {
+		tOp.GeoM.Translate(float64(placement.WindowRect.Min.X+10), float64(placement.WindowRect.Min.Y+35+i*lineHeight))
+		text.Draw(screen, line, face, tOp)
+		tOp.GeoM.Reset()
+	}
+}
@@ -0,0 +1,266 @@
+package ledger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xackery/critsprinkler/tracker"
+)
+
+// inventorySuffix ends the file /outputfile inventory writes to the EQ folder, e.g. Xackery_erollisi-Inventory.txt
+const inventorySuffix = "-Inventory.txt"
+
+var (
+	// baseline is the coin on hand from the last inventory dump, nil until one is loaded
+	baseline *Inventory
+	// inventories holds a dump found by WatchInventory until the game loop picks it up with Update
+	inventories = make(chan Inventory, 1)
+	// inventorySources holds where to look for dumps, sent by Update until WatchInventory picks it up
+	inventorySources = make(chan inventorySource, 1)
+	// lastSource is the last source sent to WatchInventory, only touched from the game loop
+	lastSource inventorySource
+)
+
+// inventorySource is where WatchInventory looks for dumps
+type inventorySource struct {
+	eqPath    string
+	character string
+}
+
+// Inventory is the coin a character had on hand when /outputfile inventory was typed
+type Inventory struct {
+	Character string
+	Time      time.Time // when the dump was written
+	Copper    int       // carried coin, in copper
+	Bank      int       // coin in the bank and shared bank, in copper
+}
+
+// Total returns carried and banked coin, in copper
+func (inv Inventory) Total() int {
+	return inv.Copper + inv.Bank
+}
+
+// ParseInventory reads the tab separated rows of an inventory dump, summing the coin rows
+func ParseInventory(r io.Reader) (Inventory, error) {
+	inv := Inventory{}
+	scanner := bufio.NewScanner(r)
+	isHeader := true
+	isCoinFound := false
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		columns := strings.Split(line, "\t")
+		if isHeader {
+			isHeader = false
+			if len(columns) < 4 || columns[0] != "Location" || columns[3] != "Count" {
+				return inv, fmt.Errorf("not an inventory file, header is %q", line)
+			}
+			continue
+		}
+		if len(columns) < 4 {
+			return inv, fmt.Errorf("line %d: %d columns, want at least 4", lineNumber, len(columns))
+		}
+		value := coinValue(columns[1])
+		if value == 0 {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(columns[3]))
+		if err != nil {
+			return inv, fmt.Errorf("line %d count: %w", lineNumber, err)
+		}
+		isCoinFound = true
+		if strings.HasPrefix(columns[0], "Bank") || strings.HasPrefix(columns[0], "SharedBank") {
+			inv.Bank += count * value
+			continue
+		}
+		inv.Copper += count * value
+	}
+	if scanner.Err() != nil {
+		return inv, fmt.Errorf("scan: %w", scanner.Err())
+	}
+	if isHeader {
+		return inv, fmt.Errorf("empty")
+	}
+	if !isCoinFound {
+		return inv, fmt.Errorf("no coin listed, the dump may be from a client that leaves currency out")
+	}
+	return inv, nil
+}
+
+// coinValue returns what one of a coin item is worth in copper, or 0 if name is not a coin
+func coinValue(name string) int {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSuffix(strings.TrimSuffix(name, " pieces"), " coins")
+	switch name {
+	case "platinum":
+		return CopperPerPlatinum
+	case "gold":
+		return CopperPerGold
+	case "silver":
+		return CopperPerSilver
+	case "copper":
+		return 1
+	}
+	return 0
+}
+
+// LoadInventory reads an inventory dump, taking the character from its file name
+func LoadInventory(path string) (Inventory, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Inventory{}, fmt.Errorf("stat: %w", err)
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		return Inventory{}, fmt.Errorf("open: %w", err)
+	}
+	defer r.Close()
+	inv, err := ParseInventory(r)
+	if err != nil {
+		return Inventory{}, err
+	}
+	inv.Time = fi.ModTime()
+	name := strings.TrimSuffix(filepath.Base(path), inventorySuffix)
+	inv.Character, _, _ = strings.Cut(name, "_")
+	return inv, nil
+}
+
+// FindInventory returns the newest inventory dump of character in eqPath, or an empty path if there is none
+func FindInventory(eqPath string, character string) (string, time.Time, error) {
+	if eqPath == "" || character == "" {
+		return "", time.Time{}, nil
+	}
+	// with a server the file is Name_server-Inventory.txt, without one Name-Inventory.txt
+	paths, err := filepath.Glob(filepath.Join(eqPath, character+"_*"+inventorySuffix))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("glob: %w", err)
+	}
+	paths = append(paths, filepath.Join(eqPath, character+inventorySuffix))
+	newest := ""
+	var newestTime time.Time
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if fi.ModTime().After(newestTime) {
+			newest = path
+			newestTime = fi.ModTime()
+		}
+	}
+	return newest, newestTime, nil
+}
+
+// WatchInventory polls for a new inventory dump until ctx is done. It looks in the EQ path for the character
+// the game loop last passed to Update, since both can change while it runs and are not safe to read from here
+func WatchInventory(ctx context.Context, interval time.Duration) {
+	var lastModTime time.Time
+	var source inventorySource
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case source = <-inventorySources:
+		default:
+		}
+		path, modTime, err := FindInventory(source.eqPath, source.character)
+		if err != nil {
+			fmt.Println("find inventory:", err)
+		}
+		if path != "" && !modTime.Equal(lastModTime) {
+			lastModTime = modTime
+			inv, err := LoadInventory(path)
+			if err != nil {
+				fmt.Println("load inventory", path+":", err)
+			} else {
+				// a newer dump replaces one the game loop has not picked up yet
+				select {
+				case <-inventories:
+				default:
+				}
+				inventories <- inv
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update passes the EQ path and character to WatchInventory, applies an inventory dump it found and
+// writes loot that waited long enough. It is meant to be polled from the game loop
+func Update(eqPath string, character string) (Inventory, bool) {
+	source := inventorySource{eqPath: eqPath, character: character}
+	if source != lastSource {
+		lastSource = source
+		// a newer source replaces one WatchInventory has not picked up yet
+		select {
+		case <-inventorySources:
+		default:
+		}
+		inventorySources <- source
+	}
+	if session != nil && !session.lootDue.IsZero() && !time.Now().Before(session.lootDue) {
+		Flush()
+	}
+	select {
+	case inv := <-inventories:
+		SetBaseline(inv)
+		return inv, true
+	default:
+		return Inventory{}, false
+	}
+}
+
+// SetBaseline sets the coin on hand that earnings are added to
+func SetBaseline(inv Inventory) {
+	baseline = &inv
+}
+
+// Baseline returns the last inventory dump, if one was loaded
+func Baseline() (Inventory, bool) {
+	if baseline == nil {
+		return Inventory{}, false
+	}
+	return *baseline, true
+}
+
+// Wealth returns the coin on hand from the last inventory dump plus what was earned since, in copper.
+// A dump of another character than the open log doesn't count.
+// It reads the session and the tracker's player name unlocked, which is only safe because it runs on the game loop
+func Wealth() (int, bool) {
+	if baseline == nil {
+		return 0, false
+	}
+	character := tracker.PlayerName()
+	if character != "" && !strings.EqualFold(character, baseline.Character) {
+		return 0, false
+	}
+	total := baseline.Total()
+	if session == nil {
+		return total, true
+	}
+	// log times are whole seconds, so the dump is compared at that resolution too.
+	// Earnings logged in the second the dump was written are counted as in it
+	dumped := baseline.Time.Truncate(time.Second)
+	for _, entry := range session.Entries {
+		if entry.Time.After(dumped) {
+			total += entry.Copper()
+		}
+	}
+	return total, true
+}
@@ -0,0 +1,143 @@
+package ledger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testInventory = "Location\tName\tID\tCount\tSlots\r\n" +
+	"Charm\tEmpty\t0\t0\t0\r\n" +
+	"General1\tBackpack\t17005\t1\t8\r\n" +
+	"General-Coin\tPlatinum\t0\t120\t0\r\n" +
+	"General-Coin\tGold\t0\t3\t0\r\n" +
+	"General-Coin\tCopper\t0\t7\t0\r\n" +
+	"Bank-Coin\tPlatinum\t0\t1000\t0\r\n" +
+	"SharedBank-Coin\tSilver\t0\t5\t0\r\n"
+
+func TestParseInventory(t *testing.T) {
+	inv, err := ParseInventory(strings.NewReader(testInventory))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if inv.Copper != 120307 || inv.Bank != 1000050 {
+		t.Errorf("got %d carried %d banked, want 120307 carried 1000050 banked", inv.Copper, inv.Bank)
+	}
+
+	_, err = ParseInventory(strings.NewReader("Location\tName\tID\tCount\tSlots\nGeneral1\tBackpack\t17005\t1\t8\n"))
+	if err == nil {
+		t.Errorf("no coin: expected an error")
+	}
+	_, err = ParseInventory(strings.NewReader("[Mon Jan 02 15:04:05 2026] Welcome to EverQuest!\n"))
+	if err == nil {
+		t.Errorf("log file: expected an error")
+	}
+}
+
+func TestWealth(t *testing.T) {
+	eqPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(eqPath, "Xackery_erollisi-Inventory.txt"), []byte(testInventory), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	path, _, err := FindInventory(eqPath, "Xackery")
+	if err != nil || path == "" {
+		t.Fatalf("find: got %q, %v", path, err)
+	}
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if inv.Character != "Xackery" {
+		t.Errorf("character: got %q, want Xackery", inv.Character)
+	}
+
+	defer func() { baseline = nil; session = nil }()
+	SetBaseline(inv)
+	session = &Session{Start: inv.Time.Add(-time.Hour), Entries: []Entry{
+		{Time: inv.Time.Add(-time.Minute), Source: SourceLoot, Currency: CurrencyPlatinum, Amount: 50},
+		{Time: inv.Time.Add(time.Minute), Source: SourceLoot, Currency: CurrencyPlatinum, Amount: 2},
+	}}
+	wealth, ok := Wealth()
+	if !ok || wealth != inv.Total()+2000 {
+		t.Errorf("wealth: got %d %t, want %d, earnings before the dump are already in it", wealth, ok, inv.Total()+2000)
+	}
+}
+
+func TestWatchInventory(t *testing.T) {
+	eqPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(eqPath, "Xackery_erollisi-Inventory.txt"), []byte(testInventory), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	defer func() { baseline = nil; lastSource = inventorySource{} }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	// later tests change time.Local, so the watcher must be gone when this one ends
+	defer func() { cancel(); <-done }()
+	go func() {
+		WatchInventory(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// the game loop tells the watcher where to look, and picks up what it found
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		inv, ok := Update(eqPath, "Xackery")
+		if !ok {
+			time.Sleep(5 * time.Millisecond)
+			continue
+		}
+		if inv.Character != "Xackery" || inv.Total() != 1120357 {
+			t.Fatalf("got %+v", inv)
+		}
+		return
+	}
+	t.Fatalf("no inventory found")
+}
+
+func TestWealthLocalTime(t *testing.T) {
+	// 10 hours ahead of UTC, where a clock mismatch would move every earning past the dump
+	local := time.Local
+	time.Local = time.FixedZone("AEST", 10*60*60)
+	defer func() { time.Local = local; baseline = nil; session = nil }()
+
+	eqPath := t.TempDir()
+	path := filepath.Join(eqPath, "Xackery_erollisi-Inventory.txt")
+	err := os.WriteFile(path, []byte(testInventory), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	dumped := time.Date(2026, 1, 2, 15, 4, 5, 400*int(time.Millisecond), time.Local)
+	err = os.Chtimes(path, dumped, dumped)
+	if err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	inv, err := LoadInventory(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	SetBaseline(inv)
+
+	// log times as the tracker parses them, in local time
+	logTime := func(clock string) time.Time {
+		event, err := time.ParseInLocation("Mon Jan 02 15:04:05 2006", "Fri Jan 02 "+clock+" 2026", time.Local)
+		if err != nil {
+			t.Fatalf("parse %s: %v", clock, err)
+		}
+		return event
+	}
+	session = &Session{Start: logTime("14:00:00"), Entries: []Entry{
+		{Time: logTime("15:03:00"), Source: SourceLoot, Currency: CurrencyPlatinum, Amount: 50},
+		{Time: logTime("15:04:05"), Source: SourceLoot, Currency: CurrencyPlatinum, Amount: 10},
+		{Time: logTime("15:05:00"), Source: SourceLoot, Currency: CurrencyPlatinum, Amount: 2},
+	}}
+	wealth, ok := Wealth()
+	if !ok || wealth != inv.Total()+2000 {
+		t.Errorf("wealth: got %d %t, want %d, only earnings after the dump's second count", wealth, ok, inv.Total()+2000)
+	}
+}
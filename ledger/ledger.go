@@ -0,0 +1,244 @@
+package ledger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xackery/critsprinkler/tracker"
+)
+
+// Source is where a currency gain came from
+type Source int
+
+const (
+	SourceLoot Source = iota
+	SourceSplit
+	SourceMerchant
+	SourceTribute
+	// SourceZone is not a gain, it marks entering a zone so time spent per zone can be measured
+	SourceZone
+	SourceTrader // an item sold to another player in the Bazaar or by an offline trader
+	SourceParcel
+	SourceReward // quest and task rewards, and LDoN adventures
+	SourceMax
+)
+
+func (e Source) String() string {
+	switch e {
+	case SourceLoot:
+		return "loot"
+	case SourceSplit:
+		return "split"
+	case SourceMerchant:
+		return "merchant"
+	case SourceTribute:
+		return "tribute"
+	case SourceZone:
+		return "zone"
+	case SourceTrader:
+		return "trader"
+	case SourceParcel:
+		return "parcel"
+	case SourceReward:
+		return "reward"
+	}
+	return "unknown"
+}
+
+// parseSource returns the source written by String
+func parseSource(value string) (Source, error) {
+	for source := Source(0); source < SourceMax; source++ {
+		if source.String() == value {
+			return source, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown source %s", value)
+}
+
+// Currency is something that can be earned, coins are kept apart so a gain can sprinkle each coin
+type Currency int
+
+const (
+	CurrencyPlatinum Currency = iota
+	CurrencyGold
+	CurrencySilver
+	CurrencyCopper
+	CurrencyFavor
+	CurrencyRadiantCrystal
+	CurrencyEbonCrystal
+	CurrencyAdventurePoint // LDoN points
+	CurrencyMax
+)
+
+func (e Currency) String() string {
+	switch e {
+	case CurrencyPlatinum:
+		return "platinum"
+	case CurrencyGold:
+		return "gold"
+	case CurrencySilver:
+		return "silver"
+	case CurrencyCopper:
+		return "copper"
+	case CurrencyFavor:
+		return "favor"
+	case CurrencyRadiantCrystal:
+		return "radiant crystal"
+	case CurrencyEbonCrystal:
+		return "ebon crystal"
+	case CurrencyAdventurePoint:
+		return "adventure point"
+	}
+	return "unknown"
+}
+
+// CopperValue returns what one of a currency is worth in copper, 0 for currencies that are not coins
+func (e Currency) CopperValue() int {
+	switch e {
+	case CurrencyPlatinum:
+		return CopperPerPlatinum
+	case CurrencyGold:
+		return CopperPerGold
+	case CurrencySilver:
+		return CopperPerSilver
+	case CurrencyCopper:
+		return 1
+	}
+	return 0
+}
+
+// parseCurrency returns the currency written by String
+func parseCurrency(value string) (Currency, error) {
+	for currency := Currency(0); currency < CurrencyMax; currency++ {
+		if currency.String() == value {
+			return currency, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown currency %s", value)
+}
+
+// copper value of each coin
+const (
+	CopperPerSilver   = 10
+	CopperPerGold     = 100
+	CopperPerPlatinum = 1000
+)
+
+// Entry is an amount of a single currency earned, or a zone change
+type Entry struct {
+	Time     time.Time
+	Zone     string
+	Source   Source
+	Currency Currency
+	Amount   int
+}
+
+// Copper returns the value of the entry in copper
+func (e Entry) Copper() int {
+	return e.Amount * e.Currency.CopperValue()
+}
+
+var (
+	dir     string
+	zone    = "Unknown"
+	session *Session
+)
+
+// New starts a session that is saved to a file in path once something is earned
+func New(path string) error {
+	if session != nil {
+		return fmt.Errorf("ledger already exists")
+	}
+	dir = path
+	// the tracker parses log times in local time, so they compare with the start
+	session = &Session{Start: time.Now()}
+	err := tracker.SubscribeToZoneEvent(onZone)
+	if err != nil {
+		return fmt.Errorf("tracker subscribe to zone: %w", err)
+	}
+	return nil
+}
+
+// Current returns the session of this run
+func Current() *Session {
+	return session
+}
+
+func onZone(event time.Time, zoneName string) {
+	// zone changes replayed from before the overlay started only tell where the player is now
+	if tracker.IsLiveParse() {
+		record(Entry{Time: event, Zone: zoneName, Source: SourceZone})
+	}
+	zone = zoneName
+}
+
+// Record adds a gain in the current zone to the session, an entry per currency
+func Record(event time.Time, gain Gain) {
+	for currency := Currency(0); currency < CurrencyMax; currency++ {
+		amount := gain.Amounts[currency]
+		if amount <= 0 {
+			continue
+		}
+		record(Entry{Time: event, Zone: zone, Source: gain.Source, Currency: currency, Amount: amount})
+	}
+}
+
+func record(entry Entry) {
+	if session == nil {
+		return
+	}
+	session.begin()
+	session.Entries = append(session.Entries, entry)
+	err := session.flush()
+	if err != nil {
+		fmt.Println("ledger save:", err)
+	}
+}
+
+// Flush writes everything the session did not save yet, e.g. before the overlay closes
+func Flush() {
+	if session == nil {
+		return
+	}
+	session.lootDue = time.Time{}
+	err := session.flush()
+	if err != nil {
+		fmt.Println("ledger save:", err)
+	}
+	err = session.flushLoot()
+	if err != nil {
+		fmt.Println("ledger loot save:", err)
+	}
+}
+
+// History returns saved sessions from before this run, newest first
+func History() ([]*Session, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+	sessions := []*Session{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".csv" || strings.HasSuffix(entry.Name(), lootSuffix) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if session != nil && path == session.path {
+			continue
+		}
+		s, err := readSession(path)
+		if err != nil {
+			fmt.Println("ledger", entry.Name(), "skipped:", err)
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
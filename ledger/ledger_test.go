@@ -0,0 +1,101 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSessionZones(t *testing.T) {
+	dir = t.TempDir()
+	start := time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC)
+	session = &Session{Start: start}
+	defer func() { session = nil }()
+
+	zone = "Crystal Caverns"
+	Record(start.Add(10*time.Minute), Gain{SourceLoot, map[Currency]int{CurrencyPlatinum: 2, CurrencyCopper: 5}})
+	if session.path == "" {
+		t.Fatalf("path: expected the session to be saved after a gain")
+	}
+	record(Entry{Time: start.Add(30 * time.Minute), Zone: "Cobalt Scar", Source: SourceZone})
+	zone = "Cobalt Scar"
+	Record(start.Add(40*time.Minute), Gain{SourceSplit, map[Currency]int{CurrencyPlatinum: 10}})
+	Record(start.Add(50*time.Minute), Gain{SourceTribute, map[Currency]int{CurrencyFavor: 300}})
+	Record(start.Add(55*time.Minute), Gain{SourceMerchant, map[Currency]int{}})
+
+	end := start.Add(90 * time.Minute)
+	if session.Copper() != 12005 {
+		t.Errorf("copper: got %d, want 12005", session.Copper())
+	}
+	if session.Amount(CurrencyFavor) != 300 {
+		t.Errorf("favor: got %d, want 300", session.Amount(CurrencyFavor))
+	}
+	if got := session.CopperPerHour(end); got != 8003.333333333333 {
+		t.Errorf("copper per hour: got %f", got)
+	}
+
+	zones := session.Zones(end)
+	if len(zones) != 2 {
+		t.Fatalf("zones: got %v", zones)
+	}
+	if zones[0].Zone != "Cobalt Scar" || zones[0].Copper != 10000 || zones[0].Duration != time.Hour || zones[0].Amounts[CurrencyFavor] != 300 {
+		t.Errorf("zones[0]: got %+v", zones[0])
+	}
+	if zones[1].Zone != "Crystal Caverns" || zones[1].Copper != 2005 || zones[1].Duration != 30*time.Minute {
+		t.Errorf("zones[1]: got %+v", zones[1])
+	}
+
+	saved, err := readSession(session.path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(saved.Entries) != len(session.Entries) || saved.Copper() != session.Copper() || !saved.Start.Equal(start) {
+		t.Errorf("read: got %d entries %d copper, want %d entries %d copper", len(saved.Entries), saved.Copper(), len(session.Entries), session.Copper())
+	}
+
+	history, err := History()
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("history: the current session should not be listed, got %d", len(history))
+	}
+}
+
+func TestFormatCopper(t *testing.T) {
+	tests := []struct {
+		copper int
+		want   string
+	}{
+		{0, "0c"},
+		{1234, "1p 2g 3s 4c"},
+		{50000, "50p"},
+		{105, "1g 5c"},
+	}
+	for _, test := range tests {
+		got := FormatCopper(test.copper)
+		if got != test.want {
+			t.Errorf("FormatCopper(%d): got %s, want %s", test.copper, got, test.want)
+		}
+	}
+}
+
+func TestReadLegacySession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2026-01-02_200000.csv")
+	err := os.WriteFile(path, []byte(`time,zone,source,copper,favor
+2026-01-02T20:00:00Z,Crystal Caverns,zone,0,0
+2026-01-02T20:10:00Z,Crystal Caverns,loot,2005,0
+2026-01-02T20:20:00Z,Crystal Caverns,split,1000,25
+`), 0644)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	s, err := readSession(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if s.Copper() != 3005 || s.Amount(CurrencyFavor) != 25 || len(s.Entries) != 4 {
+		t.Errorf("got %d copper %d favor %d entries, want 3005 copper 25 favor 4 entries", s.Copper(), s.Amount(CurrencyFavor), len(s.Entries))
+	}
+}
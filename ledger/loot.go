@@ -0,0 +1,199 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lootHeader is the first row of a session's loot file
+var lootHeader = []string{"time", "zone", "item", "count"}
+
+// lootFlushInterval is how long looted items wait to be written, so looting a full corpse is one write
+const lootFlushInterval = 5 * time.Second
+
+// lootRegex matches the player looting an item, e.g. --You have looted a Bone Chip from a skeleton's corpse.--
+var lootRegex = regexp.MustCompile(`\] --You have looted (?P<count>an?|\d+) (?P<item>.+?)(?: from .+)?\.--$`)
+
+// Loot is an item the player looted
+type Loot struct {
+	Time  time.Time
+	Zone  string
+	Item  string
+	Count int
+}
+
+// ItemCount is how many of an item were looted
+type ItemCount struct {
+	Item  string
+	Count int
+}
+
+// ParseLoot returns the item a log line looted, if any
+func ParseLoot(line string) (Loot, bool) {
+	line = strings.TrimRight(line, "\r ")
+	match := lootRegex.FindStringSubmatch(line)
+	if match == nil {
+		return Loot{}, false
+	}
+	loot := Loot{Item: match[lootRegex.SubexpIndex("item")], Count: 1}
+	count := match[lootRegex.SubexpIndex("count")]
+	if count != "a" && count != "an" {
+		val, err := strconv.Atoi(count)
+		if err != nil || val <= 0 {
+			return Loot{}, false
+		}
+		loot.Count = val
+	}
+	return loot, true
+}
+
+// RecordLoot adds an item looted in the current zone to the session, it is written by Update or Flush
+func RecordLoot(event time.Time, loot Loot) {
+	if session == nil {
+		return
+	}
+	loot.Time = event
+	loot.Zone = zone
+	session.begin()
+	session.Loot = append(session.Loot, loot)
+	if session.lootDue.IsZero() {
+		session.lootDue = time.Now().Add(lootFlushInterval)
+	}
+}
+
+// LootCount returns how many items were looted
+func (s *Session) LootCount() int {
+	total := 0
+	for _, loot := range s.Loot {
+		total += loot.Count
+	}
+	return total
+}
+
+// LootPerHour returns the items looted per hour from the start of the session until end
+func (s *Session) LootPerHour(end time.Time) float64 {
+	return perHour(s.LootCount(), end.Sub(s.Start))
+}
+
+// LootItems returns how many of each item were looted in zone, or in every zone if zone is empty.
+// The most looted item is first
+func (s *Session) LootItems(zone string) []ItemCount {
+	counts := map[string]int{}
+	for _, loot := range s.Loot {
+		if zone != "" && loot.Zone != zone {
+			continue
+		}
+		counts[loot.Item] += loot.Count
+	}
+	items := []ItemCount{}
+	for item, count := range counts {
+		items = append(items, ItemCount{Item: item, Count: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Item < items[j].Item
+	})
+	return items
+}
+
+// LootZones returns the zones something was looted in, in the order they were first looted in
+func (s *Session) LootZones() []string {
+	zones := []string{}
+	for _, loot := range s.Loot {
+		if slices.Contains(zones, loot.Zone) {
+			continue
+		}
+		zones = append(zones, loot.Zone)
+	}
+	return zones
+}
+
+// lootSuffix ends the loot file that sits beside a session file
+const lootSuffix = ".loot.csv"
+
+// lootPath returns the loot file that sits beside a session file
+func lootPath(sessionPath string) string {
+	return strings.TrimSuffix(sessionPath, ".csv") + lootSuffix
+}
+
+// flushLoot appends loot that is not saved yet to the session's loot file
+func (s *Session) flushLoot() error {
+	if s.path == "" {
+		return nil
+	}
+	w, err := os.OpenFile(lootPath(s.path), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	defer w.Close()
+	cw := csv.NewWriter(w)
+	if s.lootFlushed == 0 {
+		err = cw.Write(lootHeader)
+		if err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+	}
+	for _, loot := range s.Loot[s.lootFlushed:] {
+		err = cw.Write([]string{
+			loot.Time.Format(time.RFC3339),
+			loot.Zone,
+			loot.Item,
+			strconv.Itoa(loot.Count),
+		})
+		if err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	cw.Flush()
+	if cw.Error() != nil {
+		return fmt.Errorf("flush: %w", cw.Error())
+	}
+	s.lootFlushed = len(s.Loot)
+	return nil
+}
+
+// readLoot loads a loot file written by flushLoot, a session without loot has no file
+func readLoot(path string) ([]Loot, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer r.Close()
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	if len(rows) == 0 || !slices.Equal(rows[0], lootHeader) {
+		return nil, fmt.Errorf("unknown header")
+	}
+	loots := []Loot{}
+	for i, row := range rows[1:] {
+		line := i + 2
+		if len(row) != len(lootHeader) {
+			return nil, fmt.Errorf("row %d: %d columns, want %d", line, len(row), len(lootHeader))
+		}
+		loot := Loot{Zone: row[1], Item: row[2]}
+		loot.Time, err = time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d time: %w", line, err)
+		}
+		loot.Count, err = strconv.Atoi(row[3])
+		if err != nil {
+			return nil, fmt.Errorf("row %d count: %w", line, err)
+		}
+		loots = append(loots, loot)
+	}
+	return loots, nil
+}
@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLoot(t *testing.T) {
+	tests := []struct {
+		line string
+		want Loot
+		ok   bool
+	}{
+		{"[Mon Jan 02 15:04:05 2026] --You have looted a Bone Chip from a decaying skeleton's corpse.--", Loot{Item: "Bone Chip", Count: 1}, true},
+		{"[Mon Jan 02 15:04:05 2026] --You have looted an Ale.--\r", Loot{Item: "Ale", Count: 1}, true},
+		{"[Mon Jan 02 15:04:05 2026] --You have looted 3 Rat Whiskers from a large rat's corpse.--", Loot{Item: "Rat Whiskers", Count: 3}, true},
+		{"[Mon Jan 02 15:04:05 2026] --Buyerguy has looted a Bone Chip from a decaying skeleton's corpse.--", Loot{}, false},
+	}
+	for _, test := range tests {
+		got, ok := ParseLoot(test.line)
+		if ok != test.ok || got != test.want {
+			t.Errorf("%s: got %+v %t, want %+v %t", test.line, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestSessionLoot(t *testing.T) {
+	dir = t.TempDir()
+	start := time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC)
+	session = &Session{Start: start}
+	defer func() { session = nil; zone = "Unknown" }()
+
+	zone = "Crystal Caverns"
+	RecordLoot(start.Add(10*time.Minute), Loot{Item: "Bone Chip", Count: 1})
+	RecordLoot(start.Add(20*time.Minute), Loot{Item: "Rat Whiskers", Count: 2})
+	zone = "Cobalt Scar"
+	RecordLoot(start.Add(40*time.Minute), Loot{Item: "Bone Chip", Count: 2})
+
+	if session.LootCount() != 5 {
+		t.Errorf("count: got %d, want 5", session.LootCount())
+	}
+	if got := session.LootPerHour(start.Add(30 * time.Minute)); got != 10 {
+		t.Errorf("per hour: got %f, want 10", got)
+	}
+	want := []ItemCount{{"Bone Chip", 3}, {"Rat Whiskers", 2}}
+	if got := session.LootItems(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("items: got %v, want %v", got, want)
+	}
+	want = []ItemCount{{"Bone Chip", 2}}
+	if got := session.LootItems("Cobalt Scar"); !reflect.DeepEqual(got, want) {
+		t.Errorf("zone items: got %v, want %v", got, want)
+	}
+	if got := session.LootZones(); !reflect.DeepEqual(got, []string{"Crystal Caverns", "Cobalt Scar"}) {
+		t.Errorf("zones: got %v", got)
+	}
+
+	// loot is written in batches
+	if session.path != "" {
+		t.Fatalf("path: expected loot to wait for a flush")
+	}
+	Update("", "")
+	if session.path != "" {
+		t.Fatalf("path: expected loot to wait %s", lootFlushInterval)
+	}
+	session.lootDue = time.Now()
+	Update("", "")
+	if session.path == "" {
+		t.Fatalf("path: expected loot to save the session")
+	}
+	saved, err := readSession(session.path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !reflect.DeepEqual(saved.Loot, session.Loot) {
+		t.Errorf("read: got %+v, want %+v", saved.Loot, session.Loot)
+	}
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bazaarZone is where a sale to a player counts as a trader sale instead of a merchant sale
+const bazaarZone = "The Bazaar"
+
+// Gain is what a single log line earned
+type Gain struct {
+	Source  Source
+	Amounts map[Currency]int
+}
+
+// gainPattern is a log line that earns something. Patterns are tried in order and the first one
+// with a known currency wins, so more specific lines go before looser ones like quest rewards
+type gainPattern struct {
+	source Source
+	regex  *regexp.Regexp // has a group named amount, and optionally currency and the name of who paid
+}
+
+var (
+	gainPatterns = []gainPattern{
+		{SourceLoot, regexp.MustCompile(`\] You receive (?P<amount>.*) from the corpse\.`)},
+		{SourceSplit, regexp.MustCompile(`\] You receive (?P<amount>.*) as your split\.`)},
+		{SourceTrader, regexp.MustCompile(`\] (?:Your trader|You) sold (?:\d+ )?.+ to \w+ for \(?(?P<amount>[^)]*)\)?\.`)},
+		{SourceTrader, regexp.MustCompile(`\] (?P<name>\w+) purchased (?:\d+ )?.+ for \(?(?P<amount>[^)]*)\)?\.`)},
+		{SourceMerchant, regexp.MustCompile(`\] You receive (?P<amount>.*) from (?P<name>[^.]+) for the .+\.$`)},
+		{SourceParcel, regexp.MustCompile(`\] You (?:have )?(?:receive|received|retrieve|retrieved) (?P<amount>.*) from .*[Pp]arcel`)},
+		{SourceReward, regexp.MustCompile(`\] You (?:receive|have received|have been given) (?P<amount>.*) as (?:a|your) reward`)},
+		{SourceReward, regexp.MustCompile(`\] You receive (?P<amount>.*) from [^.]+\.$`)},
+		{SourceTribute, regexp.MustCompile(`\] You have received (?P<amount>\d+) (?P<currency>favor) for your tribute!`)},
+		{SourceLoot, regexp.MustCompile(`\] You (?:have )?(?:receive|received|gained) (?P<amount>\d+|an?) (?P<currency>Radiant|Ebon) Crystals?`)},
+		{SourceReward, regexp.MustCompile(`\] You have (?:gained|received|been awarded) (?P<amount>\d+) (?:Deepest Guk |Miragul's |Mistmoore |Rujarkian |Takish |adventure |LDoN )+(?P<currency>points?)`)},
+	}
+	coinSuffixRegex = regexp.MustCompile(`^(\d+)([pgsc])$`)
+)
+
+// Parse returns what a log line earned, if anything
+func Parse(line string) (Gain, bool) {
+	// logs written on windows keep a carriage return that would stop patterns anchored to the end
+	line = strings.TrimRight(line, "\r ")
+	for _, pattern := range gainPatterns {
+		match := pattern.regex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		group := func(name string) string {
+			index := pattern.regex.SubexpIndex(name)
+			if index < 0 {
+				return ""
+			}
+			return match[index]
+		}
+
+		// the player buying something is spending, not earning
+		if group("name") == "You" {
+			continue
+		}
+
+		gain := Gain{Source: pattern.source}
+		currency := group("currency")
+		if currency == "" {
+			gain.Amounts = parseCoins(group("amount"))
+		} else {
+			gain.Amounts = parseCurrencyAmount(group("amount"), currency)
+		}
+		if len(gain.Amounts) == 0 {
+			continue
+		}
+		// selling to a player in the Bazaar reads like a merchant sale
+		if gain.Source == SourceMerchant && zone == bazaarZone && !strings.Contains(group("name"), " ") {
+			gain.Source = SourceTrader
+		}
+		return gain, true
+	}
+	return Gain{}, false
+}
+
+// parseCoins reads coin amounts written out, e.g. 5 platinum, 3 gold and 2 copper, or short, e.g. 5p 3g 2c
+func parseCoins(text string) map[Currency]int {
+	amounts := map[Currency]int{}
+	replacer := strings.NewReplacer(",", " ", "(", " ", ")", " ")
+	val := 0
+	for _, record := range strings.Fields(replacer.Replace(text)) {
+		newVal, err := strconv.Atoi(record)
+		if err == nil {
+			val = newVal
+			continue
+		}
+		match := coinSuffixRegex.FindStringSubmatch(record)
+		if match != nil {
+			val, _ = strconv.Atoi(match[1])
+			record = map[string]string{"p": "platinum", "g": "gold", "s": "silver", "c": "copper"}[match[2]]
+		}
+		switch record {
+		case "platinum":
+			amounts[CurrencyPlatinum] += val
+		case "gold":
+			amounts[CurrencyGold] += val
+		case "silver":
+			amounts[CurrencySilver] += val
+		case "copper":
+			amounts[CurrencyCopper] += val
+		case "favor":
+			amounts[CurrencyFavor] += val
+		default:
+			continue
+		}
+		val = 0
+	}
+	for currency, amount := range amounts {
+		if amount <= 0 {
+			delete(amounts, currency)
+		}
+	}
+	return amounts
+}
+
+// parseCurrencyAmount reads a count of a currency that is not a coin, a or an count as 1
+func parseCurrencyAmount(amount string, name string) map[Currency]int {
+	val := 1
+	if amount != "a" && amount != "an" {
+		var err error
+		val, err = strconv.Atoi(amount)
+		if err != nil || val <= 0 {
+			return nil
+		}
+	}
+	switch strings.ToLower(name) {
+	case "favor":
+		return map[Currency]int{CurrencyFavor: val}
+	case "radiant":
+		return map[Currency]int{CurrencyRadiantCrystal: val}
+	case "ebon":
+		return map[Currency]int{CurrencyEbonCrystal: val}
+	case "point", "points":
+		return map[Currency]int{CurrencyAdventurePoint: val}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	defer func() { zone = "Unknown" }()
+	tests := []struct {
+		name   string
+		zone   string
+		line   string
+		source Source
+		want   map[Currency]int
+	}{
+		{"corpse", "", "[Mon Jan 02 15:04:05 2026] You receive 5 platinum, 3 gold and 2 copper from the corpse.", SourceLoot, map[Currency]int{CurrencyPlatinum: 5, CurrencyGold: 3, CurrencyCopper: 2}},
+		{"split", "", "[Mon Jan 02 15:04:05 2026] You receive 1 platinum and 4 silver as your split.", SourceSplit, map[Currency]int{CurrencyPlatinum: 1, CurrencySilver: 4}},
+		{"merchant", "", "[Mon Jan 02 15:04:05 2026] You receive 2 gold from Tamara Lightsworth for the Rusty Dagger(s).\r", SourceMerchant, map[Currency]int{CurrencyGold: 2}},
+		{"bazaar sale", bazaarZone, "[Mon Jan 02 15:04:05 2026] You receive 1500 platinum from Buyerguy for the Cloak of Flames(s).", SourceTrader, map[Currency]int{CurrencyPlatinum: 1500}},
+		{"trader purchase", "", "[Mon Jan 02 15:04:05 2026] Buyerguy purchased 2 Bone Chips for (25p 3g).", SourceTrader, map[Currency]int{CurrencyPlatinum: 25, CurrencyGold: 3}},
+		{"offline trader", "", "[Mon Jan 02 15:04:05 2026] Your trader sold 1 Cloak of Flames to Buyerguy for 1200p.", SourceTrader, map[Currency]int{CurrencyPlatinum: 1200}},
+		{"parcel", "", "[Mon Jan 02 15:04:05 2026] You have retrieved 300 platinum from your parcels.", SourceParcel, map[Currency]int{CurrencyPlatinum: 300}},
+		{"task", "", "[Mon Jan 02 15:04:05 2026] You have been given 10 platinum and 500 favor as a reward.", SourceReward, map[Currency]int{CurrencyPlatinum: 10, CurrencyFavor: 500}},
+		{"quest", "", "[Mon Jan 02 15:04:05 2026] You receive 3 gold from Guard Haldin.", SourceReward, map[Currency]int{CurrencyGold: 3}},
+		{"tribute", "", "[Mon Jan 02 15:04:05 2026] You have received 150 favor for your tribute!", SourceTribute, map[Currency]int{CurrencyFavor: 150}},
+		{"radiant", "", "[Mon Jan 02 15:04:05 2026] You receive a Radiant Crystal.", SourceLoot, map[Currency]int{CurrencyRadiantCrystal: 1}},
+		{"ebon", "", "[Mon Jan 02 15:04:05 2026] You receive 3 Ebon Crystals.", SourceLoot, map[Currency]int{CurrencyEbonCrystal: 3}},
+		{"ldon", "", "[Mon Jan 02 15:04:05 2026] You have been awarded 42 Deepest Guk points!", SourceReward, map[Currency]int{CurrencyAdventurePoint: 42}},
+	}
+	for _, test := range tests {
+		zone = test.zone
+		gain, ok := Parse(test.line)
+		if !ok {
+			t.Errorf("%s: not parsed", test.name)
+			continue
+		}
+		if gain.Source != test.source {
+			t.Errorf("%s: source got %s, want %s", test.name, gain.Source, test.source)
+		}
+		if !reflect.DeepEqual(gain.Amounts, test.want) {
+			t.Errorf("%s: amounts got %v, want %v", test.name, gain.Amounts, test.want)
+		}
+	}
+
+	for _, line := range []string{
+		"[Mon Jan 02 15:04:05 2026] You purchased 1 Bone Chips for (2p).",
+		"[Mon Jan 02 15:04:05 2026] You receive a Rusty Dagger from the corpse.",
+		"[Mon Jan 02 15:04:05 2026] Soandso says, 'You receive nothing from me.'",
+	} {
+		gain, ok := Parse(line)
+		if ok {
+			t.Errorf("%s: expected no gain, got %v", line, gain)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xackery/critsprinkler/util"
+)
+
+// maxReportedSessions keeps the history in a report short enough for a message box
+const maxReportedSessions = 10
+
+// maxReportedItems is how many of the most looted items a report lists
+const maxReportedItems = 5
+
+// FormatCopper returns copper as coins, e.g. 1234 is 1p 2g 3s 4c
+func FormatCopper(copper int) string {
+	parts := []string{}
+	for _, coin := range []struct {
+		value  int
+		suffix string
+	}{
+		{CopperPerPlatinum, "p"},
+		{CopperPerGold, "g"},
+		{CopperPerSilver, "s"},
+		{1, "c"},
+	} {
+		count := copper / coin.value
+		copper %= coin.value
+		if count == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d%s", count, coin.suffix))
+	}
+	if len(parts) == 0 {
+		return "0c"
+	}
+	return strings.Join(parts, " ")
+}
+
+// FormatPlatinumPerHour returns a copper per hour rate in platinum, e.g. 12.3p/hr
+func FormatPlatinumPerHour(copperPerHour float64) string {
+	return fmt.Sprintf("%.1fp/hr", copperPerHour/CopperPerPlatinum)
+}
+
+// Report summarizes the current session by zone, followed by earlier sessions
+func Report(now time.Time) string {
+	lines := []string{}
+	wealth, ok := Wealth()
+	if ok {
+		lines = append(lines, fmt.Sprintf("On hand: %s, from %s's inventory of %s plus earnings since",
+			FormatCopper(wealth), baseline.Character, baseline.Time.Format("Jan 2 15:04")),
+			fmt.Sprintf("  carried %s, banked %s at the time", FormatCopper(baseline.Copper), FormatCopper(baseline.Bank)),
+			"")
+	}
+	if session != nil {
+		lines = append(lines, sessionLines("This session", session, now)...)
+	}
+
+	history, err := History()
+	if err != nil {
+		lines = append(lines, "", fmt.Sprintf("Earlier sessions could not be read: %v", err))
+	}
+	if len(history) > 0 {
+		lines = append(lines, "", "Earlier sessions:")
+	}
+	for i, s := range history {
+		if i == maxReportedSessions {
+			lines = append(lines, fmt.Sprintf("...and %d more in %s", len(history)-i, dir))
+			break
+		}
+		best := ""
+		zones := s.Zones(s.End())
+		if len(zones) > 0 && zones[0].Copper > 0 {
+			best = ", best camp " + zones[0].Zone
+		}
+		looted := ""
+		if len(s.Loot) > 0 {
+			looted = fmt.Sprintf(", %d items looted", s.LootCount())
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s in %s, %s%s%s",
+			s.Start.Format("Jan 2 15:04"),
+			FormatCopper(s.Copper()),
+			util.FormatDuration(s.End().Sub(s.Start)),
+			FormatPlatinumPerHour(s.CopperPerHour(s.End())),
+			looted,
+			best,
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sessionLines returns the totals of a session followed by a line per zone
+func sessionLines(title string, s *Session, end time.Time) []string {
+	lines := []string{fmt.Sprintf("%s: %s in %s, %s", title, FormatCopper(s.Copper()), util.FormatDuration(end.Sub(s.Start)), FormatPlatinumPerHour(s.CopperPerHour(end)))}
+	other := formatAmounts(func(currency Currency) int { return s.Amount(currency) })
+	if other != "" {
+		lines = append(lines, "Also earned "+other)
+	}
+	for _, z := range s.Zones(end) {
+		line := fmt.Sprintf("  %s: %s in %s, %s", z.Zone, FormatCopper(z.Copper), util.FormatDuration(z.Duration), FormatPlatinumPerHour(z.CopperPerHour()))
+		other = formatAmounts(func(currency Currency) int { return z.Amounts[currency] })
+		if other != "" {
+			line += ", " + other
+		}
+		lines = append(lines, line)
+	}
+	return append(lines, lootLines(s, end)...)
+}
+
+// lootLines returns the items looted during a session, most looted first, then per zone
+func lootLines(s *Session, end time.Time) []string {
+	if len(s.Loot) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("Looted %d items, %.1f/hr: %s", s.LootCount(), s.LootPerHour(end), formatItems(s.LootItems("")))}
+	for _, zone := range s.LootZones() {
+		lines = append(lines, fmt.Sprintf("  %s: %s", zone, formatItems(s.LootItems(zone))))
+	}
+	return lines
+}
+
+// formatItems lists the most looted items, e.g. 3x Bone Chip, 1x Rat Whiskers
+func formatItems(items []ItemCount) string {
+	parts := []string{}
+	for i, item := range items {
+		if i == maxReportedItems {
+			parts = append(parts, fmt.Sprintf("%d more", len(items)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%dx %s", item.Count, item.Item))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatAmounts lists the currencies that are not coins, e.g. 300 favor, 2 radiant crystal
+func formatAmounts(amount func(Currency) int) string {
+	parts := []string{}
+	for currency := Currency(0); currency < CurrencyMax; currency++ {
+		if currency.CopperValue() > 0 || amount(currency) == 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", amount(currency), currency))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,298 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// minRateDuration keeps per hour rates from spiking right after a session or zone starts
+const minRateDuration = time.Minute
+
+// header is the first row of a session file
+var header = []string{"time", "zone", "source", "currency", "amount"}
+
+// legacyHeader is the first row of session files from before alternate currencies, with coins summed into copper
+var legacyHeader = []string{"time", "zone", "source", "copper", "favor"}
+
+// Session is everything earned during one run of the overlay
+type Session struct {
+	Start       time.Time
+	Entries     []Entry
+	Loot        []Loot
+	path        string    // file the session is saved to, empty until something is earned
+	flushed     int       // entries already written to path
+	lootFlushed int       // loot already written beside path
+	lootDue     time.Time // when loot waiting to be written is due, zero if there is none
+}
+
+// ZoneTotal is what was earned in a zone during a session
+type ZoneTotal struct {
+	Zone     string
+	Copper   int              // every coin, in copper
+	Amounts  map[Currency]int // currencies that are not coins
+	Duration time.Duration    // time spent in the zone
+}
+
+// CopperPerHour returns the copper earned per hour spent in the zone
+func (z ZoneTotal) CopperPerHour() float64 {
+	return perHour(z.Copper, z.Duration)
+}
+
+// Copper returns the value of every coin earned, in copper
+func (s *Session) Copper() int {
+	total := 0
+	for _, entry := range s.Entries {
+		total += entry.Copper()
+	}
+	return total
+}
+
+// Amount returns how much of a currency was earned
+func (s *Session) Amount(currency Currency) int {
+	total := 0
+	for _, entry := range s.Entries {
+		if entry.Source == SourceZone || entry.Currency != currency {
+			continue
+		}
+		total += entry.Amount
+	}
+	return total
+}
+
+// begin adds the zone the session started in, before the first entry
+func (s *Session) begin() {
+	if len(s.Entries) > 0 {
+		return
+	}
+	s.Entries = append(s.Entries, Entry{Time: s.Start, Zone: zone, Source: SourceZone})
+}
+
+// isEarning returns true once anything was earned or looted
+func (s *Session) isEarning() bool {
+	if len(s.Loot) > 0 {
+		return true
+	}
+	for _, entry := range s.Entries {
+		if entry.Source != SourceZone && entry.Amount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// End returns the time of the last entry, or the start if there are none
+func (s *Session) End() time.Time {
+	if len(s.Entries) == 0 {
+		return s.Start
+	}
+	return s.Entries[len(s.Entries)-1].Time
+}
+
+// CopperPerHour returns the copper earned per hour from the start of the session until end
+func (s *Session) CopperPerHour(end time.Time) float64 {
+	return perHour(s.Copper(), end.Sub(s.Start))
+}
+
+// Zones returns what was earned in each zone until end, the best earning zone first
+func (s *Session) Zones(end time.Time) []ZoneTotal {
+	totals := map[string]*ZoneTotal{}
+	order := []string{}
+	total := func(zone string) *ZoneTotal {
+		z, ok := totals[zone]
+		if !ok {
+			z = &ZoneTotal{Zone: zone, Amounts: map[Currency]int{}}
+			totals[zone] = z
+			order = append(order, zone)
+		}
+		return z
+	}
+
+	current := ""
+	var entered time.Time
+	for _, entry := range s.Entries {
+		if entry.Source != SourceZone {
+			z := total(entry.Zone)
+			if entry.Currency.CopperValue() > 0 {
+				z.Copper += entry.Copper()
+				continue
+			}
+			z.Amounts[entry.Currency] += entry.Amount
+			continue
+		}
+		if current != "" {
+			total(current).Duration += entry.Time.Sub(entered)
+		}
+		current = entry.Zone
+		entered = entry.Time
+		total(current)
+	}
+	if current != "" && end.After(entered) {
+		total(current).Duration += end.Sub(entered)
+	}
+
+	out := []ZoneTotal{}
+	for _, zone := range order {
+		out = append(out, *totals[zone])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Copper > out[j].Copper })
+	return out
+}
+
+func perHour(copper int, elapsed time.Duration) float64 {
+	if elapsed < minRateDuration {
+		return 0
+	}
+	return float64(copper) / elapsed.Hours()
+}
+
+// flush appends entries that are not saved yet to the session file.
+// Nothing is written until the session has earned something, so idle runs leave no files behind
+func (s *Session) flush() error {
+	if s.path == "" {
+		if !s.isEarning() {
+			return nil
+		}
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			return fmt.Errorf("create dir: %w", err)
+		}
+		s.path = filepath.Join(dir, s.Start.Format("2006-01-02_150405")+".csv")
+	}
+
+	w, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	defer w.Close()
+	cw := csv.NewWriter(w)
+	if s.flushed == 0 {
+		err = cw.Write(header)
+		if err != nil {
+			return fmt.Errorf("write header: %w", err)
+		}
+	}
+	for _, entry := range s.Entries[s.flushed:] {
+		currency := entry.Currency.String()
+		if entry.Source == SourceZone {
+			currency = ""
+		}
+		err = cw.Write([]string{
+			entry.Time.Format(time.RFC3339),
+			entry.Zone,
+			entry.Source.String(),
+			currency,
+			strconv.Itoa(entry.Amount),
+		})
+		if err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	cw.Flush()
+	if cw.Error() != nil {
+		return fmt.Errorf("flush: %w", cw.Error())
+	}
+	s.flushed = len(s.Entries)
+	return nil
+}
+
+// readSession loads a session file written by flush
+func readSession(path string) (*Session, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer r.Close()
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty")
+	}
+	isLegacy := slices.Equal(rows[0], legacyHeader)
+	if !isLegacy && !slices.Equal(rows[0], header) {
+		return nil, fmt.Errorf("unknown header %v", rows[0])
+	}
+
+	s := &Session{path: path}
+	for i, row := range rows[1:] {
+		line := i + 2
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("row %d: %d columns, want %d", line, len(row), len(header))
+		}
+		entry := Entry{Zone: row[1]}
+		entry.Time, err = time.Parse(time.RFC3339, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("row %d time: %w", line, err)
+		}
+		entry.Source, err = parseSource(row[2])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", line, err)
+		}
+		if isLegacy {
+			entries, err := legacyEntries(entry, row[3], row[4])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", line, err)
+			}
+			s.Entries = append(s.Entries, entries...)
+			continue
+		}
+		if entry.Source == SourceZone {
+			s.Entries = append(s.Entries, entry)
+			continue
+		}
+		entry.Currency, err = parseCurrency(row[3])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", line, err)
+		}
+		entry.Amount, err = strconv.Atoi(row[4])
+		if err != nil {
+			return nil, fmt.Errorf("row %d amount: %w", line, err)
+		}
+		s.Entries = append(s.Entries, entry)
+	}
+	if len(s.Entries) == 0 {
+		return nil, fmt.Errorf("no entries")
+	}
+	s.Start = s.Entries[0].Time
+	s.flushed = len(s.Entries)
+	s.Loot, err = readLoot(lootPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("loot: %w", err)
+	}
+	s.lootFlushed = len(s.Loot)
+	return s, nil
+}
+
+// legacyEntries splits a row with copper and favor columns into an entry per currency
+func legacyEntries(entry Entry, copper string, favor string) ([]Entry, error) {
+	if entry.Source == SourceZone {
+		return []Entry{entry}, nil
+	}
+	entries := []Entry{}
+	for _, column := range []struct {
+		currency Currency
+		value    string
+	}{
+		{CurrencyCopper, copper},
+		{CurrencyFavor, favor},
+	} {
+		amount, err := strconv.Atoi(column.value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", column.currency, err)
+		}
+		if amount == 0 {
+			continue
+		}
+		entry.Currency = column.currency
+		entry.Amount = amount
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
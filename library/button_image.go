@@ -12,6 +12,7 @@ const (
 	ButtonImageDefault ButtonImage = iota
 	ButtonImageInvisible
 	ButtonImageClose
+	ButtonImageCheckbox
 )
 
 // String returns the string representation of the Buttonimage
@@ -23,6 +24,8 @@ func (e ButtonImage) String() string {
 		return "ButtonImageInvisible"
 	case ButtonImageClose:
 		return "ButtonImageClose"
+	case ButtonImageCheckbox:
+		return "ButtonImageCheckbox"
 	default:
 		return "Unknown"
 	}
@@ -53,6 +56,7 @@ func buttonImageInit() error {
 		{ButtonImageDefault, NineSliceButtonIdle, NineSliceButtonHover, NineSliceButtonPressed, NineSliceButtonDisabled},
 		{ButtonImageInvisible, NineSliceButtonInvisibleIdle, NineSliceButtonInvisibleIdle, NineSliceButtonInvisibleIdle, NineSliceButtonInvisibleIdle},
 		{ButtonImageClose, NineSliceButtonCloseIdle, NineSliceButtonCloseHover, NineSliceButtonClosePressed, NineSliceButtonCloseDisabled},
+		{ButtonImageCheckbox, NineSliceCheckboxIdle, NineSliceCheckboxHover, NineSliceCheckboxHover, NineSliceCheckboxDisabled},
 	}
 
 	for _, asset := range assets {
@@ -16,6 +16,8 @@ const (
 	FontPopupNotoSansBold42
 	FontPopupNotoSansBold36
 	FontPopupNotoSansBold24
+	FontPopupNotoSansRegular42
+	FontPopupNotoSansRegular36
 )
 
 // String returns the string representation of the font
@@ -31,6 +33,10 @@ func (f Font) String() string {
 		return "FontPopupNotoSansBold36"
 	case FontPopupNotoSansBold24:
 		return "FontPopupNotoSansBold24"
+	case FontPopupNotoSansRegular42:
+		return "FontPopupNotoSansRegular42"
+	case FontPopupNotoSansRegular36:
+		return "FontPopupNotoSansRegular36"
 	default:
 		return "Unknown"
 	}
@@ -60,6 +66,8 @@ func fontInit() error {
 		{FontPopupNotoSansBold42, "assets/fonts/notosans-bold.ttf", 42},
 		{FontPopupNotoSansBold36, "assets/fonts/notosans-bold.ttf", 36},
 		{FontPopupNotoSansBold24, "assets/fonts/notosans-bold.ttf", 24},
+		{FontPopupNotoSansRegular42, "assets/fonts/notosans-regular.ttf", 42},
+		{FontPopupNotoSansRegular36, "assets/fonts/notosans-regular.ttf", 36},
 	}
 
 	for _, element := range elements {
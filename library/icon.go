@@ -1,35 +1,60 @@
 package library
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"image"
 	"image/draw"
-	"image/png"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/malashin/dds"
 )
 
+// firstIconID is the icon id of the first slot in dragitem1.dds, matching an item's icon field
+const firstIconID = 500
+
+// itemIconSize is how big a looted item sprinkles, the same as a coin
+const itemIconSize = 18
+
+// itemIconsTemplate is written when the item icon list does not exist yet
+const itemIconsTemplate = `# Looted items sprinkle with the icon of their name in the item table critsprinkler comes with.
+# Items missing from it, or that should look different, can be listed here one per line as name^icon id.
+# The icon id is the icon field of the item in the item database, e.g.
+# Bone Chip^1149
+`
+
 var (
-	icons = make(map[int]*ebiten.Image)
+	icons      = make(map[int]*ebiten.Image)
+	itemIcons  = make(map[string]int)           // lowercase item name to icon id, overriding itemIconTable
+	itemImages = make(map[string]*ebiten.Image) // lowercase item name to a sprinkle sized icon
 )
 
 // IconLoad loads all icons
 func IconLoad(eqPath string) error {
 	start := time.Now()
-	spellIconSize := image.Point{X: 40, Y: 40}
-	spellIconPadding := image.Point{X: 0, Y: 0}
-	iconRect := image.Rect(0, 0, spellIconSize.X, spellIconSize.Y)
-	totalSpellIconCount := 500
-	for i := 1; i < 179; i++ {
+	iconSize := image.Point{X: 40, Y: 40}
+	iconRect := image.Rect(0, 0, iconSize.X, iconSize.Y)
+	iconID := firstIconID
+	for i := 1; ; i++ {
 		fileName := fmt.Sprintf("dragitem%d.dds", i)
 		path := filepath.Join(eqPath, fmt.Sprintf("uifiles/default/%s", fileName))
 		data, err := os.ReadFile(path)
 		if err != nil {
+			// the number of atlases differs between clients, so the first missing one ends them
+			if os.IsNotExist(err) && i > 1 {
+				break
+			}
+			// without icons looted items still sprinkle, as a bag
+			if os.IsNotExist(err) {
+				fmt.Println("item icons not loaded,", fileName, "not found in", filepath.Dir(path))
+				break
+			}
 			return fmt.Errorf("read %s: %w", fileName, err)
 		}
 
@@ -46,43 +71,18 @@ func IconLoad(eqPath string) error {
 			return fmt.Errorf("unknown type: %T", val)
 		}
 
-		spellToDump := 590
-		//iconIndex := 0
-		for x := 0; x < nrgba.Bounds().Dx(); x += spellIconSize.X + spellIconPadding.X {
-			if x+spellIconSize.X > nrgba.Bounds().Dx() {
-				break
-			}
-			for y := 0; y < nrgba.Bounds().Dy(); y += spellIconSize.Y + spellIconPadding.Y {
-
-				if y+spellIconSize.Y > nrgba.Bounds().Dy() {
-					break
-				}
-
+		for y := 0; y+iconSize.Y <= nrgba.Bounds().Dy(); y += iconSize.Y {
+			for x := 0; x+iconSize.X <= nrgba.Bounds().Dx(); x += iconSize.X {
 				icon := image.NewRGBA(iconRect)
-				draw.Draw(icon, iconRect, nrgba.SubImage(image.Rect(x, y, x+spellIconSize.X, y+spellIconSize.Y)), image.Point{x, y}, draw.Src)
-				//fmt.Println("icon", totalSpellIconCount, "x", x, "y", y, "dx", x+spellIconSize.X, "dy", y+spellIconSize.Y)
-				// iconIndex++
-
-				if spellToDump == totalSpellIconCount {
-					w, err := os.Create(fmt.Sprintf("icon_%d.png", totalSpellIconCount))
-					if err != nil {
-						return fmt.Errorf("create icon: %w", err)
-					}
-					defer w.Close()
-
-					err = png.Encode(w, icon)
-					if err != nil {
-						return fmt.Errorf("encode icon: %w", err)
-					}
-				}
-
-				icons[totalSpellIconCount] = ebiten.NewImageFromImage(icon)
-
-				totalSpellIconCount++
+				draw.Draw(icon, iconRect, nrgba.SubImage(image.Rect(x, y, x+iconSize.X, y+iconSize.Y)), image.Point{x, y}, draw.Src)
+				icons[iconID] = ebiten.NewImageFromImage(icon)
+				iconID++
 			}
 		}
 	}
-	fmt.Printf("loaded %d icons in %0.2fs\n", totalSpellIconCount, time.Since(start).Seconds())
+	// icons were replaced, so sprinkle sized copies are stale
+	itemImages = make(map[string]*ebiten.Image)
+	fmt.Printf("loaded %d icons in %0.2fs\n", iconID-firstIconID, time.Since(start).Seconds())
 	return nil
 }
 
@@ -90,3 +90,76 @@ func IconLoad(eqPath string) error {
 func IconByID(id int) *ebiten.Image {
 	return icons[id]
 }
+
+// ItemIconsLoad reads the list of item names and their icon ids that add to or override the item table,
+// creating a commented template if there is none
+func ItemIconsLoad(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("read: %w", err)
+		}
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			return fmt.Errorf("create dir: %w", err)
+		}
+		err = os.WriteFile(path, []byte(itemIconsTemplate), 0644)
+		if err != nil {
+			return fmt.Errorf("write template: %w", err)
+		}
+		data = []byte(itemIconsTemplate)
+	}
+
+	names := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, id, ok := strings.Cut(line, "^")
+		if !ok {
+			fmt.Printf("item icons line %d: expected name^icon id, ignoring\n", lineNumber)
+			continue
+		}
+		iconID, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil {
+			fmt.Printf("item icons line %d: icon id %q is not a number, ignoring\n", lineNumber, id)
+			continue
+		}
+		names[strings.ToLower(strings.TrimSpace(name))] = iconID
+	}
+	if scanner.Err() != nil {
+		return fmt.Errorf("scan: %w", scanner.Err())
+	}
+	itemIcons = names
+	itemImages = make(map[string]*ebiten.Image)
+	return nil
+}
+
+// ItemIconByName returns a looted item's icon sized like a coin, or a loot bag if its icon is unknown
+func ItemIconByName(name string) *ebiten.Image {
+	name = strings.ToLower(name)
+	img, ok := itemImages[name]
+	if ok {
+		return img
+	}
+	id, ok := itemIcons[name]
+	if !ok {
+		id, ok = itemIconTable[name]
+	}
+	icon := icons[id]
+	if !ok || icon == nil {
+		return MiscByID(MiscLoot)
+	}
+
+	img = ebiten.NewImage(itemIconSize, itemIconSize)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(itemIconSize)/float64(icon.Bounds().Dx()), float64(itemIconSize)/float64(icon.Bounds().Dy()))
+	op.Filter = ebiten.FilterLinear
+	img.DrawImage(icon, op)
+	itemImages[name] = img
+	return img
+}
@@ -23,6 +23,17 @@ const (
 	ButtonCloseSelectedHover
 	ButtonClosePressed
 	ButtonCloseDisabled
+	ImageTextInputIdle
+	ImageTextInputDisabled
+	ImageCheckboxIdle
+	ImageCheckboxHover
+	ImageCheckboxDisabled
+	ImageCheckboxCheckedIdle
+	ImageCheckboxCheckedDisabled
+	ImageCheckboxUncheckedIdle
+	ImageCheckboxUncheckedDisabled
+	ImageCheckboxGreyedIdle
+	ImageCheckboxGreyedDisabled
 )
 
 // String returns the string representation of the image
@@ -54,6 +65,28 @@ func (e Image) String() string {
 		return "ButtonClosePressed"
 	case ButtonCloseDisabled:
 		return "ButtonCloseDisabled"
+	case ImageTextInputIdle:
+		return "ImageTextInputIdle"
+	case ImageTextInputDisabled:
+		return "ImageTextInputDisabled"
+	case ImageCheckboxIdle:
+		return "ImageCheckboxIdle"
+	case ImageCheckboxHover:
+		return "ImageCheckboxHover"
+	case ImageCheckboxDisabled:
+		return "ImageCheckboxDisabled"
+	case ImageCheckboxCheckedIdle:
+		return "ImageCheckboxCheckedIdle"
+	case ImageCheckboxCheckedDisabled:
+		return "ImageCheckboxCheckedDisabled"
+	case ImageCheckboxUncheckedIdle:
+		return "ImageCheckboxUncheckedIdle"
+	case ImageCheckboxUncheckedDisabled:
+		return "ImageCheckboxUncheckedDisabled"
+	case ImageCheckboxGreyedIdle:
+		return "ImageCheckboxGreyedIdle"
+	case ImageCheckboxGreyedDisabled:
+		return "ImageCheckboxGreyedDisabled"
 
 	default:
 		return "Unknown"
@@ -92,6 +125,17 @@ func imageInit() error {
 		{ButtonCloseSelectedHover, "assets/graphics/button-close-selected-hover.png"},
 		{ButtonClosePressed, "assets/graphics/button-close-pressed.png"},
 		{ButtonCloseDisabled, "assets/graphics/button-close-disabled.png"},
+		{ImageTextInputIdle, "assets/graphics/text-input-idle.png"},
+		{ImageTextInputDisabled, "assets/graphics/text-input-disabled.png"},
+		{ImageCheckboxIdle, "assets/graphics/checkbox-idle.png"},
+		{ImageCheckboxHover, "assets/graphics/checkbox-hover.png"},
+		{ImageCheckboxDisabled, "assets/graphics/checkbox-disabled.png"},
+		{ImageCheckboxCheckedIdle, "assets/graphics/checkbox-checked-idle.png"},
+		{ImageCheckboxCheckedDisabled, "assets/graphics/checkbox-checked-disabled.png"},
+		{ImageCheckboxUncheckedIdle, "assets/graphics/checkbox-unchecked-idle.png"},
+		{ImageCheckboxUncheckedDisabled, "assets/graphics/checkbox-unchecked-disabled.png"},
+		{ImageCheckboxGreyedIdle, "assets/graphics/checkbox-greyed-idle.png"},
+		{ImageCheckboxGreyedDisabled, "assets/graphics/checkbox-greyed-disabled.png"},
 	}
 
 	for _, asset := range assets {
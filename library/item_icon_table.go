@@ -0,0 +1,5 @@
+package library
+
+// itemIconTable is the icon id of every item by lowercase name, generated by script/genitem_test.go.
+// It is empty until the generator is run with an items export, until then items are looked up in item_icons.txt
+var itemIconTable = map[string]int{}
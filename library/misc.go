@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
+	"math"
 	"os"
 	"path/filepath"
 	"time"
@@ -21,6 +23,10 @@ const (
 	MiscSilver
 	MiscCopper
 	MiscFavor
+	MiscRadiantCrystal
+	MiscEbonCrystal
+	MiscAdventurePoint
+	MiscLoot
 )
 
 var (
@@ -32,6 +38,9 @@ func MiscLoad(eqPath string) error {
 	var err error
 	start := time.Now()
 
+	// alternate currencies have no piece in the ui files, so they are drawn
+	miscCurrencies()
+
 	err = miscCoins(eqPath)
 	if err != nil {
 		return fmt.Errorf("coins: %w", err)
@@ -88,3 +97,99 @@ func miscCoins(eqPath string) error {
 
 	return nil
 }
+
+// miscCurrencies draws icons for currencies that are not coins: crystals are gems, adventure points a token.
+// Loot without a known item icon is a bag
+func miscCurrencies() {
+	miscs[MiscRadiantCrystal] = ebiten.NewImageFromImage(gemImage(18, color.RGBA{220, 240, 255, 255}, color.RGBA{90, 150, 220, 255}))
+	miscs[MiscEbonCrystal] = ebiten.NewImageFromImage(gemImage(18, color.RGBA{120, 70, 160, 255}, color.RGBA{40, 20, 60, 255}))
+	miscs[MiscAdventurePoint] = ebiten.NewImageFromImage(tokenImage(18, color.RGBA{60, 160, 90, 255}, color.RGBA{20, 70, 35, 255}))
+	miscs[MiscLoot] = ebiten.NewImageFromImage(bagImage(18, color.RGBA{150, 105, 60, 255}, color.RGBA{70, 45, 20, 255}))
+}
+
+// gemImage returns a diamond of fill color with an edge, lit from the top left
+func gemImage(size int, fill color.RGBA, edge color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	half := float64(size-1) / 2
+	for y := range size {
+		for x := range size {
+			// distance from the center in the diamond's own metric, 1 is the outline
+			d := math.Abs(float64(x)-half)/half + math.Abs(float64(y)-half)/half
+			switch {
+			case d > 1:
+				continue
+			case d > 0.8:
+				img.SetRGBA(x, y, edge)
+			case x < size/2 && y < size/2:
+				img.SetRGBA(x, y, lighten(fill))
+			default:
+				img.SetRGBA(x, y, fill)
+			}
+		}
+	}
+	return img
+}
+
+// tokenImage returns a round token of fill color with an edge
+func tokenImage(size int, fill color.RGBA, edge color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	half := float64(size-1) / 2
+	for y := range size {
+		for x := range size {
+			d := math.Hypot(float64(x)-half, float64(y)-half) / half
+			switch {
+			case d > 1:
+				continue
+			case d > 0.75:
+				img.SetRGBA(x, y, edge)
+			case d < 0.35:
+				img.SetRGBA(x, y, lighten(fill))
+			default:
+				img.SetRGBA(x, y, fill)
+			}
+		}
+	}
+	return img
+}
+
+// bagImage returns a sack of fill color with an edge, tied off near the top
+func bagImage(size int, fill color.RGBA, edge color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	half := float64(size-1) / 2
+	neck := size / 4
+	for y := range size {
+		for x := range size {
+			dx := math.Abs(float64(x) - half)
+			switch {
+			case y < neck:
+				// the bunched cloth above the tie
+				if dx > float64(neck-y)/2+1 {
+					continue
+				}
+				img.SetRGBA(x, y, lighten(fill))
+			case y == neck:
+				if dx > 2 {
+					continue
+				}
+				img.SetRGBA(x, y, edge)
+			default:
+				// the body is the lower part of a circle
+				d := math.Hypot(dx, float64(y)-float64(size)*0.62) / (half * 0.8)
+				switch {
+				case d > 1:
+					continue
+				case d > 0.8:
+					img.SetRGBA(x, y, edge)
+				default:
+					img.SetRGBA(x, y, fill)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// lighten moves a color halfway to white
+func lighten(c color.RGBA) color.RGBA {
+	return color.RGBA{c.R/2 + 128, c.G/2 + 128, c.B/2 + 128, c.A}
+}
@@ -25,6 +25,11 @@ const (
 	NineSliceButtonCloseSelectedHover
 	NineSliceButtonClosePressed
 	NineSliceButtonCloseDisabled
+	NineSliceTextInputIdle
+	NineSliceTextInputDisabled
+	NineSliceCheckboxIdle
+	NineSliceCheckboxHover
+	NineSliceCheckboxDisabled
 )
 
 // String returns the string representation of the nineslice
@@ -62,6 +67,16 @@ func (e Nineslice) String() string {
 		return "NineSliceButtonClosePressed"
 	case NineSliceButtonCloseDisabled:
 		return "NineSliceButtonCloseDisabled"
+	case NineSliceTextInputIdle:
+		return "NineSliceTextInputIdle"
+	case NineSliceTextInputDisabled:
+		return "NineSliceTextInputDisabled"
+	case NineSliceCheckboxIdle:
+		return "NineSliceCheckboxIdle"
+	case NineSliceCheckboxHover:
+		return "NineSliceCheckboxHover"
+	case NineSliceCheckboxDisabled:
+		return "NineSliceCheckboxDisabled"
 
 	default:
 		return "Unknown"
@@ -106,6 +121,11 @@ func ninesliceInit() error {
 		{NineSliceButtonCloseSelectedHover, ButtonCloseSelectedHover, 12, 0, [3]int{0, 0, 0}, [3]int{0, 0, 0}},
 		{NineSliceButtonClosePressed, ButtonClosePressed, 0, 0, [3]int{0, 0, 0}, [3]int{0, 0, 0}},
 		{NineSliceButtonCloseDisabled, ButtonCloseDisabled, 0, 0, [3]int{0, 0, 0}, [3]int{0, 0, 0}},
+		{NineSliceTextInputIdle, ImageTextInputIdle, 0, 0, [3]int{9, 14, 6}, [3]int{9, 14, 6}},
+		{NineSliceTextInputDisabled, ImageTextInputDisabled, 0, 0, [3]int{9, 14, 6}, [3]int{9, 14, 6}},
+		{NineSliceCheckboxIdle, ImageCheckboxIdle, 0, 0, [3]int{15, 20, 15}, [3]int{0, 29, 0}},
+		{NineSliceCheckboxHover, ImageCheckboxHover, 0, 0, [3]int{15, 20, 15}, [3]int{0, 29, 0}},
+		{NineSliceCheckboxDisabled, ImageCheckboxDisabled, 0, 0, [3]int{15, 20, 15}, [3]int{0, 29, 0}},
 	}
 
 	for _, asset := range assets {
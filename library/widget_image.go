@@ -0,0 +1,42 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/ebitenui/ebitenui/widget"
+)
+
+// TextInputImage returns the images used to draw a text input
+func TextInputImage() (*widget.TextInputImage, error) {
+	idle, err := NinesliceByKey(NineSliceTextInputIdle)
+	if err != nil {
+		return nil, fmt.Errorf("idle: %w", err)
+	}
+	disabled, err := NinesliceByKey(NineSliceTextInputDisabled)
+	if err != nil {
+		return nil, fmt.Errorf("disabled: %w", err)
+	}
+	return &widget.TextInputImage{Idle: idle, Disabled: disabled}, nil
+}
+
+// CheckboxGraphicImage returns the images used to draw a checkbox's check mark
+func CheckboxGraphicImage() (*widget.CheckboxGraphicImage, error) {
+	graphics := [3]*widget.ButtonImageImage{}
+	keys := [3][2]Image{
+		{ImageCheckboxUncheckedIdle, ImageCheckboxUncheckedDisabled},
+		{ImageCheckboxCheckedIdle, ImageCheckboxCheckedDisabled},
+		{ImageCheckboxGreyedIdle, ImageCheckboxGreyedDisabled},
+	}
+	for i, key := range keys {
+		idle, err := ImageByKey(key[0])
+		if err != nil {
+			return nil, err
+		}
+		disabled, err := ImageByKey(key[1])
+		if err != nil {
+			return nil, err
+		}
+		graphics[i] = &widget.ButtonImageImage{Idle: idle, Disabled: disabled}
+	}
+	return &widget.CheckboxGraphicImage{Unchecked: graphics[0], Checked: graphics[1], Greyed: graphics[2]}, nil
+}
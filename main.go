@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	_ "embed"
+	"flag"
 	"fmt"
 	"image/color"
 	"os"
+	"time"
 
 	"github.com/ebitenui/ebitenui"
 
@@ -13,12 +16,16 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/xackery/critsprinkler/aa"
 	"github.com/xackery/critsprinkler/bubble"
+	"github.com/xackery/critsprinkler/common"
 	"github.com/xackery/critsprinkler/config"
 	"github.com/xackery/critsprinkler/dialog"
 	"github.com/xackery/critsprinkler/dps"
+	"github.com/xackery/critsprinkler/exp"
+	"github.com/xackery/critsprinkler/ledger"
 	"github.com/xackery/critsprinkler/library"
 	"github.com/xackery/critsprinkler/menu"
 	"github.com/xackery/critsprinkler/money"
+	"github.com/xackery/critsprinkler/monitor"
 	"github.com/xackery/critsprinkler/placement"
 	"github.com/xackery/critsprinkler/popup"
 	"github.com/xackery/critsprinkler/sound"
@@ -52,10 +59,19 @@ func main() {
 
 func run() error {
 	var err error
+	configPath := flag.String("config", "", "path to critsprinkler.ini, defaults to the user config directory")
+	flag.Parse()
+	err = config.SetPath(*configPath)
+	if err != nil {
+		return fmt.Errorf("config path: %w", err)
+	}
 	cfg, err = config.LoadCritSprinklerConfig()
 	if err != nil {
 		return fmt.Errorf("%s: %w", config.FileName(), err)
 	}
+	if len(cfg.Problems) > 0 {
+		dialog.MsgBox("Config Problems", config.ProblemReport(cfg.Problems))
+	}
 
 	err = library.New()
 	if err != nil {
@@ -100,19 +116,38 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("popup: %w", err)
 	}
+	err = ledger.New(config.LedgerPath())
+	if err != nil {
+		return fmt.Errorf("ledger: %w", err)
+	}
+	err = library.ItemIconsLoad(config.ItemIconsPath())
+	if err != nil {
+		// looted items still sprinkle, as a bag
+		fmt.Println("item icons:", err)
+	}
 	err = money.New(game.ui, cfg)
 	if err != nil {
 		return fmt.Errorf("money: %w", err)
 	}
+	err = exp.New(game.ui, cfg)
+	if err != nil {
+		return fmt.Errorf("exp: %w", err)
+	}
 	err = sound.New(cfg)
 	if err != nil {
 		return fmt.Errorf("sound: %w", err)
 	}
+	err = menu.ApplyCharacterProfile(cfg)
+	if err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
 
 	err = t.Start(true)
 	if err != nil {
 		return fmt.Errorf("tracker start: %w", err)
 	}
+	go config.Watch(context.Background(), time.Second)
+	go ledger.WatchInventory(context.Background(), 2*time.Second)
 	//ebiten.SetWindowSize(screenWidth, screenHeight)
 	ebiten.SetWindowTitle("CritSprinkler " + Version)
 	icons, err := library.AppIcons()
@@ -127,6 +162,15 @@ func run() error {
 	//ebiten.SetWindowFloating(true)
 	//ebiten.SetWindowPosition(0, 0)
 	fmt.Println("Showing game window")
+	if !monitor.Restore(cfg.MainMonitor) && cfg.MainMonitor != "" {
+		fmt.Println("monitor", cfg.MainMonitor, "not found, using", monitor.Current())
+	}
+	monitorWidth, monitorHeight := ebiten.Monitor().Size()
+	if !cfg.MainWindowRelative.IsZero() {
+		cfg.MainWindow = cfg.MainWindowRelative.Scale(monitorWidth, monitorHeight)
+	}
+	// the saved position may be off screen if the monitor is gone or its resolution changed
+	cfg.MainWindow = monitor.Clamp(cfg.MainWindow)
 	ebiten.SetWindowPosition(cfg.MainWindow.Min.X, cfg.MainWindow.Min.Y)
 	ebiten.SetWindowSize(cfg.MainWindow.Dx(), cfg.MainWindow.Dy())
 	ebiten.SetWindowSizeLimits(700, 700, -1, -1)
@@ -136,6 +180,8 @@ func run() error {
 		ScreenTransparent: true,
 		//InitUnfocused:     true,
 	})
+	// loot waiting for its batch would be lost otherwise
+	ledger.Flush()
 	if err != nil {
 		return fmt.Errorf("rungame: %v", err)
 	}
@@ -156,6 +202,16 @@ func (g *Game) Update() error {
 		g.ui.Update()
 	}
 
+	if config.IsChanged() {
+		err := reloadConfig()
+		if err != nil {
+			fmt.Println("reload config:", err)
+			status.Setf("%s was not reloaded: %v", config.FileName(), err)
+		}
+	}
+
+	placement.Update(g.IsEditMode())
+	tracker.Update()
 	bubble.Update()
 	popup.Update()
 	money.Update()
@@ -184,8 +240,12 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.statusBarDraw(screen)
 	}
 	g.ui.Draw(screen)
+	if g.IsEditMode() {
+		placement.Draw(screen)
+	}
 	popup.Draw(screen)
 	money.Draw(screen)
+	exp.Draw(screen)
 	//win.GetActiveWindowTitle() == "EverQuest"
 
 	//	}
@@ -212,6 +272,7 @@ func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 func (g *Game) onResize() {
 	placement.OnResize()
 	money.OnResize()
+	exp.OnResize()
 }
 
 func updateSave() error {
@@ -220,10 +281,13 @@ func updateSave() error {
 		return fmt.Errorf("config not loaded")
 	}
 
+	cfg.MainMonitor = monitor.Current()
 	cfg.MainWindow.Min.X, cfg.MainWindow.Min.Y = ebiten.WindowPosition()
 	cfg.MainWindow.Max.X, cfg.MainWindow.Max.Y = ebiten.WindowSize()
 	cfg.MainWindow.Max.X += cfg.MainWindow.Min.X
 	cfg.MainWindow.Max.Y += cfg.MainWindow.Min.Y
+	monitorWidth, monitorHeight := ebiten.Monitor().Size()
+	cfg.MainWindowRelative = common.ScaledRect(cfg.MainWindow, monitorWidth, monitorHeight)
 
 	err = cfg.Save()
 	if err != nil {
@@ -233,12 +297,56 @@ func updateSave() error {
 	return nil
 }
 
+// reloadConfig applies an edited config file to the running overlay.
+// The file is fully parsed and validated before anything is applied, so a broken edit leaves the current settings alone
+func reloadConfig() error {
+	next, err := config.LoadCritSprinklerConfig()
+	if err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+	// an edit in progress may not parse yet, so nothing is applied until the whole file is good
+	if len(next.Problems) > 0 {
+		fmt.Println(config.ProblemReport(next.Problems))
+		return fmt.Errorf("%d problem(s), first %s", len(next.Problems), next.Problems[0].String())
+	}
+	logPath := cfg.LogPath
+	cfg.Apply(next)
+
+	err = menu.ConfigUpdate(cfg)
+	if err != nil {
+		return fmt.Errorf("menu: %w", err)
+	}
+	if cfg.LogPath != logPath && cfg.LogPath != "" {
+		err = tracker.SetNewPath(cfg.LogPath)
+		if err != nil {
+			return fmt.Errorf("tracker: %w", err)
+		}
+	}
+	err = placement.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("placement: %w", err)
+	}
+	popup.ConfigUpdate(cfg)
+	err = money.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("money: %w", err)
+	}
+	err = exp.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("exp: %w", err)
+	}
+	sound.ConfigUpdate()
+	status.Setf("Reloaded %s", config.FileName())
+	return nil
+}
+
 // SetEditMode sets if the game is in edit mode
 func (g *Game) setEditMode(isEditMode bool) {
 	g.isEditMode = isEditMode
 	menu.SetEditMode(g.ui, isEditMode)
 	placement.SetEditMode(g.ui, isEditMode)
 	money.SetEditMode(g.ui, isEditMode)
+	exp.SetEditMode(g.ui, isEditMode)
 	fmt.Println("edit mode is now", isEditMode)
 	go func() {
 		ebiten.SetWindowMousePassthrough(!isEditMode)
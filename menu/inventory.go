@@ -0,0 +1,25 @@
+package menu
+
+import (
+	"fmt"
+
+	"github.com/xackery/critsprinkler/config"
+	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/ledger"
+	"github.com/xackery/critsprinkler/status"
+)
+
+// inventoryImport sets the money baseline from a dump picked by hand, e.g. one of another character
+func inventoryImport(cfg *config.CritSprinklerConfiguration) error {
+	path, err := dialog.InventoryFileDialogBox(cfg.EQPath)
+	if err != nil {
+		return err
+	}
+	inv, err := ledger.LoadInventory(path)
+	if err != nil {
+		return fmt.Errorf("load: %w", err)
+	}
+	ledger.SetBaseline(inv)
+	status.Setf("%s has %s on hand", inv.Character, ledger.FormatCopper(inv.Total()))
+	return nil
+}
@@ -0,0 +1,226 @@
+package menu
+
+import (
+	"fmt"
+	goimage "image"
+	"image/color"
+	"os"
+
+	"github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xackery/critsprinkler/config"
+	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/exp"
+	"github.com/xackery/critsprinkler/money"
+	"github.com/xackery/critsprinkler/placement"
+	"github.com/xackery/critsprinkler/popup"
+	"github.com/xackery/critsprinkler/status"
+)
+
+var (
+	// layoutPreview is the bar shown while an imported layout is being tried out
+	layoutPreview *widget.Window
+	// layoutRevert is the config from before the import, restored if the preview is reverted
+	layoutRevert *config.CritSprinklerConfiguration
+)
+
+// layoutEntries returns the File menu entries to share a layout
+func layoutEntries(cfg *config.CritSprinklerConfiguration) []*widget.Button {
+	entries := []struct {
+		label string
+		hover string
+		click func() error
+	}{
+		{"Copy Layout", "Copy the layout to the clipboard as a single line to paste in chat", func() error { return layoutCopy(cfg) }},
+		{"Paste Layout", "Preview a layout copied from someone else", func() error { return layoutPaste(cfg) }},
+		{"Export Layout...", "Save the layout to a file", func() error { return layoutExport(cfg) }},
+		{"Import Layout...", "Preview a layout from a file", func() error { return layoutImport(cfg) }},
+	}
+	buttons := []*widget.Button{}
+	for _, entry := range entries {
+		button := toolbarButtonNew(entry.label, defaultFont)
+		button.Configure(
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				err := entry.click()
+				if err != nil && err.Error() != "cancelled" {
+					dialog.MsgBox("Error", fmt.Sprintf("%s: %v", entry.label, err))
+				}
+			}),
+			widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set(entry.hover) }),
+			widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+		)
+		if layoutPreview != nil {
+			button.GetWidget().Disabled = true
+		}
+		buttons = append(buttons, button)
+	}
+	return buttons
+}
+
+func layoutCopy(cfg *config.CritSprinklerConfiguration) error {
+	text, err := cfg.ExportBundle()
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	data, err := config.EncodeBundle(text)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	err = dialog.SetClipboardText(data)
+	if err != nil {
+		return fmt.Errorf("clipboard: %w", err)
+	}
+	status.Setf("Copied the layout to the clipboard (%d characters)", len(data))
+	return nil
+}
+
+func layoutPaste(cfg *config.CritSprinklerConfiguration) error {
+	data, err := dialog.ClipboardText()
+	if err != nil {
+		return fmt.Errorf("clipboard: %w", err)
+	}
+	return layoutPreviewOpen(cfg, "the clipboard", data)
+}
+
+func layoutExport(cfg *config.CritSprinklerConfiguration) error {
+	path, err := dialog.LayoutFileDialogBox(true)
+	if err != nil {
+		return err
+	}
+	text, err := cfg.ExportBundle()
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	err = os.WriteFile(path, []byte(text), 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	status.Setf("Exported the layout to %s", path)
+	return nil
+}
+
+func layoutImport(cfg *config.CritSprinklerConfiguration) error {
+	path, err := dialog.LayoutFileDialogBox(false)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	return layoutPreviewOpen(cfg, path, string(data))
+}
+
+// layoutPreviewOpen applies a shared layout to the running overlay, and shows a bar to keep or revert it
+func layoutPreviewOpen(cfg *config.CritSprinklerConfiguration, source string, data string) error {
+	next, problems, err := cfg.PreviewBundle(data)
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Println("layout", problem.String())
+	}
+
+	layoutRevert = cfg.Clone()
+	cfg.Apply(next)
+	err = layoutReload(cfg)
+	if err != nil {
+		layoutClose(cfg, false)
+		return fmt.Errorf("apply: %w", err)
+	}
+
+	message := fmt.Sprintf("Previewing the layout from %s", source)
+	if len(problems) > 0 {
+		message += fmt.Sprintf(", %d line(s) could not be used", len(problems))
+	}
+
+	c := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(image.NewNineSliceColor(color.RGBA{R: 0, G: 0, B: 0, A: 200})),
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+			widget.RowLayoutOpts.Spacing(8),
+			widget.RowLayoutOpts.Padding(widget.NewInsetsSimple(6)),
+		)),
+	)
+	c.AddChild(widget.NewText(
+		widget.TextOpts.Text(message, defaultFont, color.White),
+		widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{Position: widget.RowLayoutPositionCenter})),
+	))
+
+	btnKeep := toolbarButtonNew("Keep", defaultFont)
+	btnKeep.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { layoutClose(cfg, true) }),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) {
+			status.Setf("Keep this layout and save it to %s", config.Path())
+		}),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+	c.AddChild(btnKeep)
+
+	btnRevert := toolbarButtonNew("Revert", defaultFont)
+	btnRevert.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { layoutClose(cfg, false) }),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("Go back to the layout from before the import") }),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+	c.AddChild(btnRevert)
+
+	w, h := c.PreferredSize()
+	screenW, _ := ebiten.WindowSize()
+	top := toolbar.container.GetWidget().Rect.Max.Y + 4
+	layoutPreview = widget.NewWindow(
+		widget.WindowOpts.Contents(c),
+		widget.WindowOpts.Location(goimage.Rect((screenW-w)/2, top, (screenW+w)/2, top+h)),
+	)
+	ui.AddWindow(layoutPreview)
+	status.Set(message)
+	return nil
+}
+
+// layoutClose ends a preview, saving the imported layout if it is kept or putting the old one back
+func layoutClose(cfg *config.CritSprinklerConfiguration, isKept bool) {
+	if layoutPreview != nil {
+		layoutPreview.Close()
+		layoutPreview = nil
+	}
+	if layoutRevert == nil {
+		return
+	}
+	if isKept {
+		layoutRevert = nil
+		err := cfg.Save()
+		if err != nil {
+			dialog.MsgBox("Error", fmt.Sprintf("Error saving layout: %v", err))
+			return
+		}
+		status.Set("Kept the imported layout")
+		return
+	}
+	cfg.Apply(layoutRevert)
+	layoutRevert = nil
+	err := layoutReload(cfg)
+	if err != nil {
+		dialog.MsgBox("Error", fmt.Sprintf("Error reverting layout: %v", err))
+		return
+	}
+	status.Set("Reverted the imported layout")
+}
+
+// layoutReload reopens placements and popups after the layout settings changed
+func layoutReload(cfg *config.CritSprinklerConfiguration) error {
+	err := placement.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("placement: %w", err)
+	}
+	popup.ConfigUpdate(cfg)
+	err = money.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("money: %w", err)
+	}
+	err = exp.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("exp: %w", err)
+	}
+	return nil
+}
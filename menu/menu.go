@@ -15,6 +15,7 @@ import (
 
 var (
 	onEQPathLoad func()
+	loadedEQPath string // EQPath spells and misc assets were last loaded from
 )
 
 // New creates a new UI
@@ -39,7 +40,7 @@ func New(cfg *config.CritSprinklerConfiguration, onSave func(), onEQPathLoadSrc
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
 			onSave()
 		}),
-		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Setf("Save changes to %s", config.FileName()) }),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Setf("Save changes to %s", config.Path()) }),
 		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
 	)
 
@@ -75,6 +76,8 @@ func eqPathLoad(cfg *config.CritSprinklerConfiguration) error {
 		return nil
 	}
 
+	loadedEQPath = path
+
 	err := spell.Load(filepath.Join(path, "spells_us.txt"))
 	if err != nil {
 		return fmt.Errorf("spell load: %w", err)
@@ -89,6 +92,19 @@ func eqPathLoad(cfg *config.CritSprinklerConfiguration) error {
 	if err != nil {
 		return fmt.Errorf("misc load: %w", err)
 	}
+
+	err = library.IconLoad(path)
+	if err != nil {
+		return fmt.Errorf("icon load: %w", err)
+	}
 	onEQPathLoad()
 	return nil
 }
+
+// ConfigUpdate reloads eq assets if the config now points at another eq path
+func ConfigUpdate(cfg *config.CritSprinklerConfiguration) error {
+	if cfg.EQPath == loadedEQPath {
+		return nil
+	}
+	return eqPathLoad(cfg)
+}
@@ -0,0 +1,160 @@
+package menu
+
+import (
+	"fmt"
+
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/xackery/critsprinkler/config"
+	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/exp"
+	"github.com/xackery/critsprinkler/money"
+	"github.com/xackery/critsprinkler/placement"
+	"github.com/xackery/critsprinkler/status"
+	"github.com/xackery/critsprinkler/tracker"
+)
+
+// profileMenuOpen lists layout profiles, the active one is marked with a *
+func profileMenuOpen(cfg *config.CritSprinklerConfiguration, opener *widget.Widget) {
+	entries := []*widget.Button{}
+	for _, name := range config.ProfileNames() {
+		label := name
+		if name == cfg.Profile {
+			label = "* " + name
+		}
+		if !config.IsProfileSaved(name) {
+			label += " (empty)"
+		}
+		entry := toolbarButtonNew(label, defaultFont)
+		entry.Configure(
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				profileActionMenuOpen(cfg, name, args.Button.GetWidget())
+			}),
+			widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Setf("Load, save or bind the %s layout", name) }),
+			widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+		)
+		entries = append(entries, entry)
+	}
+	toolbarMenuOpen(opener, ui, entries...)
+}
+
+// profileActionMenuOpen shows what can be done with a single profile
+func profileActionMenuOpen(cfg *config.CritSprinklerConfiguration, name string, opener *widget.Widget) {
+	character := tracker.PlayerName()
+
+	btnLoad := toolbarButtonNew("Load", defaultFont)
+	btnLoad.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			err := profileLoad(cfg, name)
+			if err != nil {
+				dialog.MsgBox("Error", fmt.Sprintf("Error loading profile: %v", err))
+			}
+		}),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Setf("Switch to the %s layout", name) }),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+	if !config.IsProfileSaved(name) {
+		btnLoad.GetWidget().Disabled = true
+	}
+
+	btnSave := toolbarButtonNew("Save Current Layout", defaultFont)
+	btnSave.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			err := cfg.SaveProfile(name)
+			if err != nil {
+				dialog.MsgBox("Error", fmt.Sprintf("Error saving profile: %v", err))
+				return
+			}
+			// the saved profile became the active one
+			err = cfg.Save()
+			if err != nil {
+				dialog.MsgBox("Error", fmt.Sprintf("Error saving config: %v", err))
+				return
+			}
+			status.Setf("Saved layout to %s", name)
+		}),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) {
+			status.Setf("Save the current placements as the %s layout", name)
+		}),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+
+	isBound := character != "" && cfg.ProfileByCharacter(character) == name
+	bindLabel := fmt.Sprintf("Bind To %s", character)
+	if isBound {
+		bindLabel = fmt.Sprintf("Unbind From %s", character)
+	}
+	btnBind := toolbarButtonNew(bindLabel, defaultFont)
+	btnBind.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			profile := name
+			if isBound {
+				profile = ""
+			}
+			cfg.BindProfile(character, profile)
+			err := cfg.Save()
+			if err != nil {
+				dialog.MsgBox("Error", fmt.Sprintf("Error saving config: %v", err))
+				return
+			}
+			if isBound {
+				status.Setf("Unbound the %s layout from %s", name, character)
+				return
+			}
+			status.Setf("Bound the %s layout to %s", name, character)
+		}),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) {
+			status.Setf("Load the %s layout whenever %s's log is opened", name, character)
+		}),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+	if character == "" || !config.IsProfileSaved(name) {
+		btnBind.GetWidget().Disabled = true
+	}
+
+	toolbarMenuOpen(opener, ui, btnLoad, btnSave, btnBind)
+}
+
+// profileLoad switches to a layout profile and reopens every placement
+func profileLoad(cfg *config.CritSprinklerConfiguration, name string) error {
+	err := cfg.LoadProfile(name)
+	if err != nil {
+		return err
+	}
+	// ConfigUpdate picks up a font the profile changed, Reload alone keeps the old faces
+	err = placement.ConfigUpdate()
+	if err != nil {
+		return fmt.Errorf("placement config update: %w", err)
+	}
+	err = money.Reload()
+	if err != nil {
+		return fmt.Errorf("money reload: %w", err)
+	}
+	err = exp.Reload()
+	if err != nil {
+		return fmt.Errorf("exp reload: %w", err)
+	}
+	// the loaded profile became the active one
+	err = cfg.Save()
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	status.Setf("Switched to the %s layout", name)
+	return nil
+}
+
+// ApplyCharacterProfile loads the profile bound to the current character, if any
+func ApplyCharacterProfile(cfg *config.CritSprinklerConfiguration) error {
+	character := tracker.PlayerName()
+	if character == "" {
+		return nil
+	}
+	name := cfg.ProfileByCharacter(character)
+	if name == "" || name == cfg.Profile {
+		return nil
+	}
+	if !config.IsProfileSaved(name) {
+		fmt.Println("profile", name, "bound to", character, "does not exist")
+		return nil
+	}
+	return profileLoad(cfg, name)
+}
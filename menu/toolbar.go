@@ -14,8 +14,10 @@ import (
 	"github.com/xackery/critsprinkler/common"
 	"github.com/xackery/critsprinkler/config"
 	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/exp"
 	"github.com/xackery/critsprinkler/library"
 	"github.com/xackery/critsprinkler/money"
+	"github.com/xackery/critsprinkler/monitor"
 	"github.com/xackery/critsprinkler/placement"
 	"github.com/xackery/critsprinkler/status"
 	"golang.org/x/image/colornames"
@@ -66,7 +68,10 @@ type toolbarStruct struct {
 	btnTotalHealOut         *widget.Button
 	btnTotalHealIn          *widget.Button
 	mnuExtra                *widget.Button
+	mnuProfile              *widget.Button
 	btnMoney                *widget.Button
+	btnInventoryImport      *widget.Button
+	btnExp                  *widget.Button
 }
 
 func toolbarNew(cfg *config.CritSprinklerConfiguration, eui *ebitenui.UI) (*toolbarStruct, error) {
@@ -115,6 +120,11 @@ func toolbarNew(cfg *config.CritSprinklerConfiguration, eui *ebitenui.UI) (*tool
 				dialog.MsgBox("Error", fmt.Sprintf("Error loading eq assets: %v", err))
 				return
 			}
+			err = ApplyCharacterProfile(cfg)
+			if err != nil {
+				dialog.MsgBox("Error", fmt.Sprintf("Error loading profile: %v", err))
+				return
+			}
 		}),
 		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) {
 			if cfg.LogPath == "" {
@@ -130,7 +140,10 @@ func toolbarNew(cfg *config.CritSprinklerConfiguration, eui *ebitenui.UI) (*tool
 	toolbar.mnuFile.Configure(
 		// Make the toolbar entry open a menu with our "save" and "load" entries  when the user clicks it.
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-			toolbarMenuOpen(args.Button.GetWidget(), ui, toolbar.btnFileLoadEQLog, toolbar.btnFileSave, toolbar.btnFileQuit)
+			entries := []*widget.Button{toolbar.btnFileLoadEQLog, toolbar.btnFileSave}
+			entries = append(entries, layoutEntries(cfg)...)
+			entries = append(entries, toolbar.btnFileQuit)
+			toolbarMenuOpen(args.Button.GetWidget(), ui, entries...)
 		}),
 	)
 	toolbar.container.AddChild(toolbar.mnuFile)
@@ -147,7 +160,10 @@ func toolbarNew(cfg *config.CritSprinklerConfiguration, eui *ebitenui.UI) (*tool
 
 	toolbar.menuSettings.Configure(
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-			toolbarMenuOpen(args.Button.GetWidget(), ui, toolbar.btnFullscreenBorderless)
+			entries := []*widget.Button{toolbar.btnFullscreenBorderless}
+			entries = append(entries, arrangeEntries(cfg)...)
+			entries = append(entries, monitorEntries(cfg)...)
+			toolbarMenuOpen(args.Button.GetWidget(), ui, entries...)
 		}),
 	)
 	toolbar.container.AddChild(toolbar.menuSettings)
@@ -230,7 +246,14 @@ func toolbarNew(cfg *config.CritSprinklerConfiguration, eui *ebitenui.UI) (*tool
 	toolbar.container.AddChild(toolbar.mnuExtra)
 	toolbar.mnuExtra.Configure(
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-			toolbarMenuOpen(args.Button.GetWidget(), ui, toolbar.btnMoney)
+			toolbarMenuOpen(args.Button.GetWidget(), ui, toolbar.btnMoney, toolbar.btnExp, toolbar.btnInventoryImport)
+		}))
+
+	toolbar.mnuProfile = toolbarButtonNew("Profile", defaultFont)
+	toolbar.container.AddChild(toolbar.mnuProfile)
+	toolbar.mnuProfile.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			profileMenuOpen(cfg, args.Button.GetWidget())
 		}))
 
 	toolbar.btnMoney = toolbarButtonNew("Money", defaultFont)
@@ -242,6 +265,29 @@ func toolbarNew(cfg *config.CritSprinklerConfiguration, eui *ebitenui.UI) (*tool
 		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
 	)
 
+	toolbar.btnExp = toolbarButtonNew("Experience", defaultFont)
+	toolbar.btnExp.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			exp.Toggle()
+		}),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("Toggle Experience") }),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+
+	toolbar.btnInventoryImport = toolbarButtonNew("Import Inventory...", defaultFont)
+	toolbar.btnInventoryImport.Configure(
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			err := inventoryImport(cfg)
+			if err != nil && err.Error() != "cancelled" {
+				dialog.MsgBox("Error", fmt.Sprintf("Import Inventory: %v", err))
+			}
+		}),
+		widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) {
+			status.Set("Set the coin on hand from an /outputfile inventory dump")
+		}),
+		widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+	)
+
 	return toolbar, nil
 }
 
@@ -328,9 +374,9 @@ func toolbarMenuOpen(opener *widget.Widget, ui *ebitenui.UI, entries ...*widget.
 		widget.WindowOpts.Location(
 			goimage.Rect(
 				opener.Rect.Min.X,
-				opener.Rect.Min.Y+opener.Rect.Max.Y,
+				opener.Rect.Max.Y,
 				opener.Rect.Min.X+w,
-				opener.Rect.Min.Y+opener.Rect.Max.Y+opener.Rect.Min.Y+h,
+				opener.Rect.Max.Y+h,
 			),
 		),
 	)
@@ -339,6 +385,69 @@ func toolbarMenuOpen(opener *widget.Widget, ui *ebitenui.UI, entries ...*widget.
 	ui.AddWindow(window)
 }
 
+// arrangeEntries returns a menu entry per preset layout for the open placements and the money and experience windows
+func arrangeEntries(cfg *config.CritSprinklerConfiguration) []*widget.Button {
+	entries := []*widget.Button{}
+	for arrangement := placement.Arrangement(0); arrangement < placement.ArrangementMax; arrangement++ {
+		entry := toolbarButtonNew("Arrange "+arrangement.String(), defaultFont)
+		entry.Configure(
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				placement.Arrange(arrangement, &cfg.Money, &cfg.Exp)
+			}),
+			widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) {
+				status.Setf("Move every open placement into a %s layout", arrangement.String())
+			}),
+			widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+		)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// monitorEntries returns a menu entry per connected monitor to move the overlay to it
+func monitorEntries(cfg *config.CritSprinklerConfiguration) []*widget.Button {
+	entries := []*widget.Button{}
+	current := monitor.Current()
+	for _, name := range monitor.Names() {
+		label := "Move To " + name
+		if name == current {
+			label = "* " + name
+		}
+		entry := toolbarButtonNew(label, defaultFont)
+		entry.Configure(
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				moveToMonitor(cfg, name)
+			}),
+			widget.ButtonOpts.CursorMovedHandler(func(args *widget.ButtonHoverEventArgs) { status.Setf("Show the overlay on %s", name) }),
+			widget.ButtonOpts.CursorExitedHandler(func(args *widget.ButtonHoverEventArgs) { status.Set("") }),
+		)
+		if name == current {
+			entry.GetWidget().Disabled = true
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func moveToMonitor(cfg *config.CritSprinklerConfiguration, name string) {
+	cfg.MainMonitor = name
+	go func() {
+		if !monitor.Restore(name) {
+			return
+		}
+		if cfg.IsFullscreenBorderless {
+			ebiten.SetWindowPosition(0, 0)
+			ebiten.SetWindowSize(ebiten.Monitor().Size())
+			return
+		}
+		x, y := ebiten.WindowPosition()
+		w, h := ebiten.WindowSize()
+		rect := monitor.Clamp(goimage.Rect(x, y, x+w, y+h))
+		ebiten.SetWindowPosition(rect.Min.X, rect.Min.Y)
+		ebiten.SetWindowSize(rect.Dx(), rect.Dy())
+	}()
+}
+
 func toggleFullscreenBorderless(cfg *config.CritSprinklerConfiguration) {
 	cfg.IsFullscreenBorderless = !cfg.IsFullscreenBorderless
 	go func() {
@@ -5,8 +5,6 @@ import (
 	"image"
 	"image/color"
 	"math"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/ebitenui/ebitenui"
@@ -15,17 +13,27 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/xackery/critsprinkler/common"
 	"github.com/xackery/critsprinkler/config"
+	"github.com/xackery/critsprinkler/dialog"
+	"github.com/xackery/critsprinkler/ledger"
 	"github.com/xackery/critsprinkler/library"
 	"github.com/xackery/critsprinkler/sound"
+	"github.com/xackery/critsprinkler/status"
 	"github.com/xackery/critsprinkler/tracker"
 	"github.com/xackery/critsprinkler/util"
 	"golang.org/x/exp/rand"
 )
 
 const (
-	gravity        = 0.3
+	// referenceTick is the tick length the coin physics were originally tuned for
+	referenceTick = time.Second / 60
+	// maxFrameDelta caps how far coins advance in one update
+	maxFrameDelta = 250 * time.Millisecond
+
+	gravity        = 1080 // pixels per second squared
 	bounceFactor   = 0.8
-	horizontalDrag = 0.98
+	horizontalDrag = 0.98 // velocity kept every reference tick
+	restSpeed      = 60   // pixels per second below which a bounce comes to rest
+	meltDuration   = 100 * referenceTick
 )
 
 var (
@@ -52,14 +60,23 @@ var (
 	copperBuffer    int
 	upgradeTimer    time.Time
 
-	favorImage   *ebiten.Image
-	sprinkleChan = make(chan showerEvent, 1000)
-	sprinkles    []*sprinkle
+	// currencies that are not coins count up like coins, but are never exchanged. Looted items count as MiscLoot
+	otherKinds     = []library.Misc{library.MiscFavor, library.MiscRadiantCrystal, library.MiscEbonCrystal, library.MiscAdventurePoint, library.MiscLoot}
+	otherCollected = map[library.Misc]int{}
+	otherBuffer    = map[library.Misc]int{}
+
+	// showers are queued by log lines and the title bar, both on the game loop, until Update sprinkles them.
+	// A slice rather than a channel, so a burst of lines can't block the loop that drains it
+	showers    []showerEvent
+	sprinkles  []*sprinkle
+	lastUpdate time.Time
+	isSized    bool // set once the overlay size is known
 )
 
 type showerEvent struct {
 	Currency library.Misc
 	Amount   int
+	image    *ebiten.Image // overrides the currency's icon, e.g. a looted item
 }
 
 type sprinkle struct {
@@ -67,16 +84,24 @@ type sprinkle struct {
 	Amount         int
 	IsTallyEnabled bool
 	image          *ebiten.Image
-	maxLife        int
-	life           int
 	x, y           float64
 	vx, vy         float64
 	fade           float32
+	isSupported    bool    // on the floor or on another coin this update
+	isOnCoin       bool    // resting on another coin, so it slides less
+	rested         float64 // seconds spent at rest
+	isCollecting   bool    // flying to its counter
+	from           point   // where it started flying from
+	collected      float64 // how far it has flown, 0 to 1
 }
 
 func New(eui *ebitenui.UI, ecfg *config.CritSprinklerConfiguration) error {
 	cfg = ecfg
 	placement = &cfg.Money
+	if placement.Relative.IsZero() {
+		// older configs only have pixels, measured on the overlay size they were saved with
+		placement.Capture(*placement.WindowRect, cfg.MainWindow.Dx(), cfg.MainWindow.Dy())
+	}
 	ui = eui
 	err := tracker.Subscribe(onLine)
 	if err != nil {
@@ -150,7 +175,7 @@ func Open() error {
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
 
 			sound.Play(sound.SoundEffectBuyItem)
-			sprinkleChan <- showerEvent{library.MiscSilver, 10}
+			showers = append(showers, showerEvent{Currency: library.MiscSilver, Amount: 10})
 		}),
 		widget.ButtonOpts.TabOrder(99),
 	))
@@ -162,7 +187,7 @@ func Open() error {
 			Disabled: util.HexToColor("5A7A91FF"),
 		}),
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-
+			dialog.MsgBox("Money Ledger", ledger.Report(time.Now()))
 		}),
 		widget.ButtonOpts.TabOrder(99),
 	))
@@ -206,12 +231,10 @@ func Open() error {
 		widget.WindowOpts.MinSize(100, 80),
 		//widget.WindowOpts.MaxSize(300, 1000),
 		widget.WindowOpts.MoveHandler(func(args *widget.WindowChangedEventArgs) {
-			*placement.WindowRect = args.Rect
-			OnResize()
+			onMoved(args.Rect)
 		}),
 		widget.WindowOpts.ResizeHandler(func(args *widget.WindowChangedEventArgs) {
-			*placement.WindowRect = args.Rect
-			OnResize()
+			onMoved(args.Rect)
 		}),
 	)
 	//windowSize := input.GetWindowSize()
@@ -220,6 +243,10 @@ func Open() error {
 	//r = r.Add(image.Point{windowSize.X / 4 / 2, windowSize.Y * 2 / 3 / 5})
 
 	//window.SetLocation(r)
+	if isSized {
+		w, h := ebiten.WindowSize()
+		*placement.WindowRect = common.ClampRect(placement.Resolve(w, h), w, h)
+	}
 	placement.Window.SetLocation(*placement.WindowRect)
 
 	placement.IsVisible = 1
@@ -238,6 +265,25 @@ func Close() error {
 	return nil
 }
 
+// Reload reopens the money window after its settings were replaced, e.g. by a layout profile
+func Reload() error {
+	isVisible := placement.IsVisible
+	if placement.Window != nil {
+		placement.Window.Close()
+		placement.Window = nil
+	}
+	if isVisible == 0 {
+		placement.IsVisible = 0
+		return nil
+	}
+	return Open()
+}
+
+// ConfigUpdate applies reloaded money settings
+func ConfigUpdate() error {
+	return Reload()
+}
+
 // Toggle opens or closes the window
 func Toggle() error {
 	if placement.IsVisible == 1 {
@@ -247,87 +293,32 @@ func Toggle() error {
 }
 
 func Update() {
-	for {
-		isFound := false
-		select {
-		case sprinkle := <-sprinkleChan:
-			fmt.Println("sprinkle", sprinkle)
-			for i := 0; i < sprinkle.Amount; i++ {
-				sprinkleOut(sprinkle.Currency, 1)
-			}
-			isFound = true
-		default:
-		}
-		if !isFound {
-			break
+	for _, shower := range showers {
+		fmt.Println("sprinkle", shower)
+		for i := 0; i < shower.Amount; i++ {
+			sprinkleOut(shower.Currency, 1, shower.image)
 		}
 	}
+	showers = showers[:0]
 
-	for i := len(sprinkles) - 1; i >= 0; i-- {
-		sprinkle := sprinkles[i]
-
-		sprinkle.life--
-		sprinkle.vy += gravity
-		sprinkle.y += sprinkle.vy
-		sprinkle.x += sprinkle.vx
-		sprinkle.vx *= horizontalDrag
-
-		ground := placement.WindowRect.Max.Y - placement.WindowRect.Min.Y - 20
-
-		if sprinkle.y > float64(ground) {
-			sprinkle.y = float64(ground)
-
-			sprinkle.vy = -sprinkle.vy * bounceFactor
-
-			if math.Abs(sprinkle.vy) < 1 {
-				sprinkle.vy = 0
-			} else {
-
-				//sound.PlayBounceRandom()
-			}
-		}
-		if sprinkle.vy == 0 {
-			sprinkle.fade -= 0.01
-		}
-
-		// if we are at the target, remove the sprinkle
-		// if int(sprinkle.x) == *sprinkle.baseX && int(sprinkle.y) == *sprinkle.baseY {
-		// 	switch sprinkle.Currency {
-		// 	case library.MiscPlatinum:
-		// 		platinumBuffer += sprinkle.Amount
-		// 	case library.MiscGold:
-		// 		goldBuffer += sprinkle.Amount
-		// 	case library.MiscSilver:
-		// 		silverBuffer += sprinkle.Amount
-		// 	case library.MiscCopper:
-		// 		copperBuffer += sprinkle.Amount
-		// 	}
-
-		// 	sprinkles = append(sprinkles[:i], sprinkles[i+1:]...)
-		//} else if sprinkle.life <= 0 {
-		if sprinkle.fade <= 0 {
-			switch sprinkle.Currency {
-			case library.MiscPlatinum:
-				platinumBuffer += sprinkle.Amount
-			case library.MiscGold:
-				goldBuffer += sprinkle.Amount
-			case library.MiscSilver:
-				silverBuffer += sprinkle.Amount
-			case library.MiscCopper:
-				copperBuffer += sprinkle.Amount
-			}
-			upgradeTimer = time.Now().Add(time.Second * 3)
-			sprinkles = append(sprinkles[:i], sprinkles[i+1:]...)
+	inv, ok := ledger.Update(cfg.EQPath, tracker.PlayerName())
+	if ok {
+		status.Setf("Coin on hand set from %s's inventory: %s", inv.Character, ledger.FormatCopper(inv.Total()))
+	}
 
-		}
-		//}
+	dt := util.Elapsed(&lastUpdate, maxFrameDelta)
+	ticks := dt / referenceTick.Seconds()
+	step(dt)
 
+	// count up by half the remaining distance every reference tick
+	countUp := 1 - math.Pow(0.5, ticks)
+	platinumCollected = bufferApply(platinumCollected, platinumBuffer, countUp)
+	goldCollected = bufferApply(goldCollected, goldBuffer, countUp)
+	silverCollected = bufferApply(silverCollected, silverBuffer, countUp)
+	copperCollected = bufferApply(copperCollected, copperBuffer, countUp)
+	for _, kind := range otherKinds {
+		otherCollected[kind] = bufferApply(otherCollected[kind], otherBuffer[kind], countUp)
 	}
-
-	platinumCollected = bufferApply(platinumCollected, platinumBuffer)
-	goldCollected = bufferApply(goldCollected, goldBuffer)
-	silverCollected = bufferApply(silverCollected, silverBuffer)
-	copperCollected = bufferApply(copperCollected, copperBuffer)
 	if upgradeTimer.Before(time.Now()) {
 		for copperBuffer >= 10 {
 			copperBuffer -= 10
@@ -347,30 +338,25 @@ func Update() {
 
 }
 
+// OnResize places the money window for the current overlay size
 func OnResize() {
 	w, h := ebiten.WindowSize()
-
-	rect := placement.Window.GetContainer().GetWidget().Rect
-	originalRect := rect
-
-	newMinX := util.ClampInt(rect.Min.X, 0, w-rect.Dx())
-	newMaxX := newMinX + rect.Dx()
-
-	newMinY := util.ClampInt(rect.Min.Y, 0, h-rect.Dy())
-	newMaxY := newMinY + rect.Dy()
-
-	newRect := rect
-	newRect.Min.X = newMinX
-	newRect.Max.X = newMaxX
-	newRect.Min.Y = newMinY
-	newRect.Max.Y = newMaxY
-
-	// Apply the changes only if the rectangle has changed
-	if newRect != originalRect {
-		fmt.Println("going from", originalRect, "to", newRect)
-		placement.Window.SetLocation(newRect)
-		*placement.WindowRect = newRect
+	isSized = true
+	rect := common.ClampRect(placement.Resolve(w, h), w, h)
+	*placement.WindowRect = rect
+	if placement.Window == nil {
+		return
 	}
+	placement.Window.SetLocation(rect)
+}
+
+// onMoved keeps the money window on screen after it was dragged or resized and remembers where it is
+func onMoved(rect image.Rectangle) {
+	w, h := ebiten.WindowSize()
+	rect = common.ClampRect(rect, w, h)
+	placement.Window.SetLocation(rect)
+	*placement.WindowRect = rect
+	placement.Capture(rect, w, h)
 }
 
 func Draw(screen *ebiten.Image) {
@@ -387,6 +373,7 @@ func Draw(screen *ebiten.Image) {
 	dOp := &ebiten.DrawImageOptions{}
 	tOp := &text.DrawOptions{}
 	txt := ""
+	counters[library.MiscPlatinum] = point{x - float64(windowX), y - float64(windowY)}
 	if platinumImage != nil {
 		dOp.GeoM.Translate(x, y)
 		screen.DrawImage(platinumImage, dOp)
@@ -394,11 +381,12 @@ func Draw(screen *ebiten.Image) {
 	}
 	x += 20
 	tOp.GeoM.Translate(x, y)
-	txt = fmt.Sprintf("%d", platinumCollected)
+	txt = cfg.NumberFormat().Format(platinumCollected)
 	tW, _ := text.Measure(txt, face, 0)
 	text.Draw(screen, txt, face, tOp)
 	tOp.GeoM.Reset()
 	x += tW
+	counters[library.MiscGold] = point{x - float64(windowX), y - float64(windowY)}
 	if goldImage != nil {
 		dOp.GeoM.Translate(x, y)
 		screen.DrawImage(goldImage, dOp)
@@ -406,11 +394,12 @@ func Draw(screen *ebiten.Image) {
 	}
 	x += 20
 	tOp.GeoM.Translate(x, y)
-	txt = fmt.Sprintf("%d", goldCollected)
+	txt = cfg.NumberFormat().Format(goldCollected)
 	tW, _ = text.Measure(txt, face, 0)
 	text.Draw(screen, txt, face, tOp)
 	tOp.GeoM.Reset()
 	x += tW
+	counters[library.MiscSilver] = point{x - float64(windowX), y - float64(windowY)}
 	if silverImage != nil {
 		dOp.GeoM.Translate(x, y)
 		screen.DrawImage(silverImage, dOp)
@@ -418,11 +407,12 @@ func Draw(screen *ebiten.Image) {
 	}
 	x += 20
 	tOp.GeoM.Translate(x, y)
-	txt = fmt.Sprintf("%d", silverCollected)
+	txt = cfg.NumberFormat().Format(silverCollected)
 	tW, _ = text.Measure(txt, face, 0)
 	text.Draw(screen, txt, face, tOp)
 	tOp.GeoM.Reset()
 	x += tW
+	counters[library.MiscCopper] = point{x - float64(windowX), y - float64(windowY)}
 	if copperImage != nil {
 		dOp.GeoM.Translate(x, y)
 		screen.DrawImage(copperImage, dOp)
@@ -430,10 +420,54 @@ func Draw(screen *ebiten.Image) {
 	}
 	x += 20
 	tOp.GeoM.Translate(x, y)
-	txt = fmt.Sprintf("%d", copperCollected)
+	txt = cfg.NumberFormat().Format(copperCollected)
 	text.Draw(screen, txt, face, tOp)
 	tOp.GeoM.Reset()
 
+	// a row above the coins for anything else earned this run
+	x = float64(windowX + 10)
+	y -= 22
+	for _, kind := range otherKinds {
+		if otherCollected[kind] == 0 {
+			continue
+		}
+		counters[kind] = point{x - float64(windowX), y - float64(windowY)}
+		kindImage := library.MiscByID(kind)
+		if kindImage != nil {
+			dOp.GeoM.Translate(x, y)
+			screen.DrawImage(kindImage, dOp)
+			dOp.GeoM.Reset()
+		}
+		x += 20
+		tOp.GeoM.Translate(x, y)
+		txt = cfg.NumberFormat().Format(otherCollected[kind])
+		tW, _ = text.Measure(txt, face, 0)
+		text.Draw(screen, txt, face, tOp)
+		tOp.GeoM.Reset()
+		x += tW + 6
+	}
+
+	session := ledger.Current()
+	if session != nil {
+		// the ledger rate sits under the title bar
+		tOp.GeoM.Translate(float64(windowX+10), float64(windowY+35))
+		perHour := session.CopperPerHour(time.Now())
+		txt = fmt.Sprintf("%sp this session, %sp/hr", cfg.NumberFormat().Format(session.Copper()/ledger.CopperPerPlatinum), cfg.NumberFormat().Format(int(perHour/ledger.CopperPerPlatinum)))
+		if len(session.Loot) > 0 {
+			txt += fmt.Sprintf(", %d items looted", session.LootCount())
+		}
+		text.Draw(screen, txt, face, tOp)
+		tOp.GeoM.Reset()
+	}
+
+	wealth, ok := ledger.Wealth()
+	if ok {
+		tOp.GeoM.Translate(float64(windowX+10), float64(windowY+50))
+		txt = fmt.Sprintf("%sp on hand", cfg.NumberFormat().Format(wealth/ledger.CopperPerPlatinum))
+		text.Draw(screen, txt, face, tOp)
+		tOp.GeoM.Reset()
+	}
+
 	for _, sprinkle := range sprinkles {
 
 		if sprinkle.image == nil {
@@ -459,6 +493,18 @@ func Draw(screen *ebiten.Image) {
 			case library.MiscFavor:
 				rgba = color.RGBA{255, 255, 255, 255}
 				msg = "f"
+			case library.MiscRadiantCrystal:
+				rgba = color.RGBA{220, 240, 255, 255}
+				msg = "r"
+			case library.MiscEbonCrystal:
+				rgba = color.RGBA{150, 90, 200, 255}
+				msg = "e"
+			case library.MiscAdventurePoint:
+				rgba = color.RGBA{60, 200, 90, 255}
+				msg = "a"
+			case library.MiscLoot:
+				rgba = color.RGBA{150, 105, 60, 255}
+				msg = "l"
 			}
 			op.ColorScale.ScaleWithColor(rgba)
 			text.Draw(screen, msg, face, op)
@@ -487,195 +533,72 @@ func OnEQPathLoad() {
 	goldImage = library.MiscByID(library.MiscGold)
 	silverImage = library.MiscByID(library.MiscSilver)
 	copperImage = library.MiscByID(library.MiscCopper)
-	favorImage = library.MiscByID(library.MiscFavor)
 }
 
 func onLine(event time.Time, line string) {
-	if parseCorpse(line) {
-		return
-	}
-	if parseCorpseSplit(line) {
-		return
-	}
-	if parseMerchant(line) {
-		return
-	}
-	if parseTribute(line) {
+	loot, ok := ledger.ParseLoot(line)
+	if ok {
+		showers = append(showers, showerEvent{Currency: library.MiscLoot, Amount: loot.Count, image: library.ItemIconByName(loot.Item)})
+		if tracker.IsLiveParse() {
+			ledger.RecordLoot(event, loot)
+		}
 		return
 	}
-}
 
-func parseCorpse(line string) bool {
-	match, ok := util.Parse(line, `\] You receive (.*) from the corpse.`, 1)
+	gain, ok := ledger.Parse(line)
 	if !ok {
-		return false
-	}
-	var val int
-	records := strings.Split(match[0], " ")
-	for _, record := range records {
-		newVal, err := strconv.Atoi(record)
-		if err == nil {
-			val = newVal
-			continue
-		}
-		record = strings.ReplaceAll(record, "and", "")
-		record = strings.TrimSpace(strings.ReplaceAll(record, ",", ""))
-		if len(record) < 1 {
-			continue
-		}
-		fmt.Println(record)
-
-		sound.Play(sound.SoundEffectBuyItem)
-		switch record {
-		case "platinum":
-			sprinkleChan <- showerEvent{library.MiscPlatinum, val}
-		case "gold":
-			sprinkleChan <- showerEvent{library.MiscGold, val}
-		case "silver":
-			sprinkleChan <- showerEvent{library.MiscSilver, val}
-		case "copper":
-			sprinkleChan <- showerEvent{library.MiscCopper, val}
-		default:
-			fmt.Println("unknown money type", record)
-		}
+		return
 	}
-	return true
-}
-
-func parseCorpseSplit(line string) bool {
-	match, ok := util.Parse(line, `\] You receive (.*) as your split.`, 1)
-	if !ok {
-		return false
-	}
-	var val int
-	records := strings.Split(match[0], " ")
-	for _, record := range records {
-		newVal, err := strconv.Atoi(record)
-		if err == nil {
-			val = newVal
-			continue
-		}
-		record = strings.ReplaceAll(record, "and", "")
-		record = strings.TrimSpace(strings.ReplaceAll(record, ",", ""))
-		if len(record) < 1 {
+	sound.Play(sound.SoundEffectBuyItem)
+	for currency := ledger.Currency(0); currency < ledger.CurrencyMax; currency++ {
+		amount := gain.Amounts[currency]
+		if amount <= 0 {
 			continue
 		}
-		fmt.Println(record)
-
-		sound.Play(sound.SoundEffectBuyItem)
-		switch record {
-		case "platinum":
-			sprinkleChan <- showerEvent{library.MiscPlatinum, val}
-		case "gold":
-			sprinkleChan <- showerEvent{library.MiscGold, val}
-		case "silver":
-			sprinkleChan <- showerEvent{library.MiscSilver, val}
-		case "copper":
-			sprinkleChan <- showerEvent{library.MiscCopper, val}
-		case "favor":
-			sprinkleChan <- showerEvent{library.MiscFavor, val}
-		default:
-			fmt.Println("unknown money type", record)
-		}
+		showers = append(showers, showerEvent{Currency: currencyMisc(currency), Amount: amount})
 	}
-	return true
-}
-
-func parseMerchant(line string) bool {
-	match, ok := util.Parse(line, `\] You receive (.*) from .* for .*.`, 1)
-	if !ok {
-		return false
-	}
-	var val int
-	records := strings.Split(match[0], " ")
-	for _, record := range records {
-		newVal, err := strconv.Atoi(record)
-		if err == nil {
-			val = newVal
-			continue
-		}
-		record = strings.ReplaceAll(record, "and", "")
-		record = strings.TrimSpace(strings.ReplaceAll(record, ",", ""))
-		if len(record) < 1 {
-			continue
-		}
-		fmt.Println(record)
-
-		sound.Play(sound.SoundEffectBuyItem)
-
-		switch record {
-		case "platinum":
-			sprinkleChan <- showerEvent{library.MiscPlatinum, val}
-		case "gold":
-			sprinkleChan <- showerEvent{library.MiscGold, val}
-		case "silver":
-			sprinkleChan <- showerEvent{library.MiscSilver, val}
-		case "copper":
-			sprinkleChan <- showerEvent{library.MiscCopper, val}
-		default:
-			fmt.Println("unknown money type", record)
-		}
+	// lines replayed from before the overlay started belong to an earlier session
+	if tracker.IsLiveParse() {
+		ledger.Record(event, gain)
 	}
-	return true
 }
 
-func parseTribute(line string) bool {
-	match, ok := util.Parse(line, `\] You have received (.*) favor for your tribute!`, 1)
-	if !ok {
-		return false
-	}
-	var val int
-	newVal, err := strconv.Atoi(match[0])
-	if err == nil {
-		val = newVal
-	}
-	sound.Play(sound.SoundEffectBuyItem)
-	sprinkleChan <- showerEvent{library.MiscFavor, val}
-	return true
+// currencyMisc returns the icon a currency sprinkles as
+func currencyMisc(currency ledger.Currency) library.Misc {
+	switch currency {
+	case ledger.CurrencyPlatinum:
+		return library.MiscPlatinum
+	case ledger.CurrencyGold:
+		return library.MiscGold
+	case ledger.CurrencySilver:
+		return library.MiscSilver
+	case ledger.CurrencyCopper:
+		return library.MiscCopper
+	case ledger.CurrencyFavor:
+		return library.MiscFavor
+	case ledger.CurrencyRadiantCrystal:
+		return library.MiscRadiantCrystal
+	case ledger.CurrencyEbonCrystal:
+		return library.MiscEbonCrystal
+	case ledger.CurrencyAdventurePoint:
+		return library.MiscAdventurePoint
+	}
+	return library.MiscCopper
 }
 
-func sprinkleOut(currency library.Misc, val int) {
-	if len(sprinkles) >= 100000 {
-		switch currency {
-		case library.MiscPlatinum:
-			platinumBuffer += val
-		case library.MiscGold:
-			goldBuffer += val
-		case library.MiscSilver:
-			silverBuffer += val
-		case library.MiscCopper:
-			copperBuffer += val
-		}
+// sprinkleOut throws a currency into the window, drawn as img if set
+func sprinkleOut(currency library.Misc, val int, img *ebiten.Image) {
+	// past the budget coins skip the window and count straight away
+	if len(sprinkles) >= cfg.MoneyMaxSprinkles {
+		collect(currency, val)
 		return
 	}
-	vx := float64(0)
-	vy := float64(-0.5 - rand.Float64() - (rand.Float64() / 2))
-	switch placement.Direction {
-	case common.DirectionUp:
-		vy = -0.5 - rand.Float64() - (rand.Float64() / 2)
-		vx = 0
-	case common.DirectionDown:
-		vy = 0.5 + rand.Float64() + (rand.Float64() / 2)
-		vx = 0
-	case common.DirectionLeft:
-		vy = 0
-		vx = -0.5 - rand.Float64() - (rand.Float64() / 2)
-	case common.DirectionRight:
-		vy = 0
-		vx = 0.5 + rand.Float64() + (rand.Float64() / 2)
-	case common.DirectionUpLeft:
-		vy = -0.5 - rand.Float64() - (rand.Float64() / 2)
-		vx = -0.5 - rand.Float64() - (rand.Float64() / 2)
-	case common.DirectionUpRight:
-		vy = -0.5 - rand.Float64() - (rand.Float64() / 2)
-		vx = 0.5 + rand.Float64() + (rand.Float64() / 2)
-	case common.DirectionDownLeft:
-		vy = 0.5 + rand.Float64() + (rand.Float64() / 2)
-		vx = -0.5 - rand.Float64() - (rand.Float64() / 2)
-	case common.DirectionDownRight:
-		vy = 0.5 + rand.Float64() + (rand.Float64() / 2)
-		vx = 0.5 + rand.Float64() + (rand.Float64() / 2)
+	if img == nil {
+		img = library.MiscByID(currency)
 	}
+	dx, dy := placement.Direction.Vector()
+	vx := dx * util.RandomSpeed()
+	vy := dy * util.RandomSpeed()
 
 	x := randomSpawnRange(int(placement.LastSpawnX), 0, placement.WindowRect.Dx()-50, placement.WindowRect.Dx()/4, 0)
 	y := randomSpawnRange(int(placement.LastSpawnY), 0, placement.WindowRect.Dy()-50, placement.WindowRect.Dy()/4, 0)
@@ -684,13 +607,11 @@ func sprinkleOut(currency library.Misc, val int) {
 		Currency:       currency,
 		Amount:         val,
 		IsTallyEnabled: placement.IsTallyEnabled == 1,
-		image:          library.MiscByID(currency),
+		image:          img,
 		x:              x,
 		y:              y,
 		vx:             vx,
 		vy:             vy,
-		maxLife:        600,
-		life:           600,
 		fade:           1,
 	})
 
@@ -699,6 +620,7 @@ func sprinkleOut(currency library.Misc, val int) {
 
 }
 
+// randomSpawnRange returns a coin position between minPos and maxPos, trying to keep it tolerance away from the last one
 func randomSpawnRange(lastSpawnX, minPos, maxPos, tolerance, maxAttempts int) float64 {
 	if minPos == 0 && maxPos == 0 {
 		return 0
@@ -721,12 +643,12 @@ func randomSpawnRange(lastSpawnX, minPos, maxPos, tolerance, maxAttempts int) fl
 	return float64(minPos + rand.Intn(maxPos-minPos))
 }
 
-func bufferApply(collected, buffer int) int {
+func bufferApply(collected, buffer int, countUp float64) int {
 	if collected == buffer {
 		return collected
 	}
 	if collected < buffer {
-		delta := int((float64(buffer-collected) * 0.5))
+		delta := int((float64(buffer-collected) * countUp))
 		if delta < 1 {
 			delta = 1
 		}
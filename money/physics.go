@@ -0,0 +1,218 @@
+package money
+
+import (
+	"math"
+	"time"
+
+	"github.com/xackery/critsprinkler/library"
+	"github.com/xackery/critsprinkler/sound"
+)
+
+const (
+	coinRadius       = 9   // coins collide as circles the size of their icon
+	coinBounceFactor = 0.4 // coins hitting each other lose more speed than on the floor
+	pileFriction     = 0.8 // horizontal velocity kept every reference tick by a coin resting on another
+	collisionPasses  = 3   // more passes settle tall piles faster
+	bounceSoundSpeed = 200 // pixels per second an impact needs to clink
+
+	collectDelay    = 500 * time.Millisecond // how long a coin rests before flying to its counter
+	collectDuration = 600 * time.Millisecond
+)
+
+// point is a position inside the money window
+type point struct {
+	x, y float64
+}
+
+var (
+	counters  = map[library.Misc]point{} // where each counter's icon was last drawn
+	lastClink time.Time
+)
+
+// step moves every sprinkle forward by dt seconds, then removes the ones that melted or reached their counter
+func step(dt float64) {
+	ticks := dt / referenceTick.Seconds()
+	ground := float64(placement.WindowRect.Dy() - 20)
+	wall := float64(placement.WindowRect.Dx() - 2*coinRadius)
+
+	for _, s := range sprinkles {
+		if s.isCollecting {
+			continue
+		}
+		s.vy += gravity * dt
+		s.y += s.vy * dt
+		s.x += s.vx * dt
+		s.vx *= math.Pow(horizontalDrag, ticks)
+		s.isSupported = false
+		s.isOnCoin = false
+		// the sides keep piles from being pushed out of the window
+		if s.x < 0 || s.x > wall {
+			s.x = math.Max(0, math.Min(s.x, wall))
+			s.vx = -s.vx * bounceFactor
+		}
+		if s.y < ground {
+			continue
+		}
+		s.y = ground
+		s.isSupported = true
+		if s.vy > restSpeed {
+			clink(s.vy)
+		}
+		s.vy = -s.vy * bounceFactor
+		if math.Abs(s.vy) < restSpeed {
+			s.vy = 0
+		}
+	}
+
+	for range collisionPasses {
+		collide(ground, wall)
+	}
+
+	for i := len(sprinkles) - 1; i >= 0; i-- {
+		s := sprinkles[i]
+		if s.isCollecting {
+			s.collected += dt / collectDuration.Seconds()
+			if s.collected >= 1 {
+				collect(s.Currency, s.Amount)
+				sprinkles = append(sprinkles[:i], sprinkles[i+1:]...)
+				continue
+			}
+			target := counters[s.Currency]
+			// ease in and out so coins leave the pile slowly and land softly
+			t := s.collected * s.collected * (3 - 2*s.collected)
+			s.x = s.from.x + (target.x-s.from.x)*t
+			s.y = s.from.y + (target.y-s.from.y)*t
+			continue
+		}
+
+		if s.isOnCoin {
+			s.vx *= math.Pow(pileFriction, ticks)
+		}
+		if !s.isSupported || math.Abs(s.vy) >= restSpeed {
+			s.rested = 0
+			continue
+		}
+		s.vy = 0
+		s.rested += dt
+
+		_, hasCounter := counters[s.Currency]
+		if cfg.MoneyIsCollectAnimated && hasCounter {
+			if s.rested >= collectDelay.Seconds() {
+				s.isCollecting = true
+				s.from = point{s.x, s.y}
+			}
+			continue
+		}
+		s.fade -= float32(dt / meltDuration.Seconds())
+		if s.fade <= 0 {
+			collect(s.Currency, s.Amount)
+			sprinkles = append(sprinkles[:i], sprinkles[i+1:]...)
+		}
+	}
+}
+
+// collide pushes overlapping coins apart and trades their speed, so falling coins pile up on resting ones
+func collide(ground float64, wall float64) {
+	size := 2 * coinRadius
+	cells := map[[2]int][]*sprinkle{}
+	for _, s := range sprinkles {
+		if s.isCollecting {
+			continue
+		}
+		cell := [2]int{int(s.x) / size, int(s.y) / size}
+		cells[cell] = append(cells[cell], s)
+	}
+
+	for cell, group := range cells {
+		for i, a := range group {
+			// coins later in the same cell, then every coin in the cells after this one
+			others := group[i+1:]
+			for _, offset := range [][2]int{{1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+				others = append(others[:len(others):len(others)], cells[[2]int{cell[0] + offset[0], cell[1] + offset[1]}]...)
+			}
+			for _, b := range others {
+				collidePair(a, b, ground, wall)
+			}
+		}
+	}
+}
+
+// collidePair separates two coins if they overlap, without pushing either out of the window
+func collidePair(a, b *sprinkle, ground float64, wall float64) {
+	dx := b.x - a.x
+	dy := b.y - a.y
+	dist := math.Hypot(dx, dy)
+	if dist >= 2*coinRadius {
+		return
+	}
+	nx, ny := 1.0, 0.0
+	if dist > 0 {
+		nx, ny = dx/dist, dy/dist
+	}
+
+	// a coin on the floor can't be pushed into it, so the other coin moves the whole way
+	aShare, bShare := 0.5, 0.5
+	switch {
+	case a.y >= ground && ny < 0:
+		aShare, bShare = 0, 1
+	case b.y >= ground && ny > 0:
+		aShare, bShare = 1, 0
+	}
+	overlap := 2*coinRadius - dist
+	a.x = math.Max(0, math.Min(a.x-nx*overlap*aShare, wall))
+	a.y = math.Min(a.y-ny*overlap*aShare, ground)
+	b.x = math.Max(0, math.Min(b.x+nx*overlap*bShare, wall))
+	b.y = math.Min(b.y+ny*overlap*bShare, ground)
+
+	// mostly above the other coin means resting on it
+	if ny > 0.5 {
+		a.isSupported = true
+		a.isOnCoin = true
+	}
+	if ny < -0.5 {
+		b.isSupported = true
+		b.isOnCoin = true
+	}
+
+	closing := (b.vx-a.vx)*nx + (b.vy-a.vy)*ny
+	if closing >= 0 {
+		return
+	}
+	if -closing > bounceSoundSpeed {
+		clink(-closing)
+	}
+	impulse := -(1 + coinBounceFactor) * closing / 2
+	a.vx -= impulse * nx
+	a.vy -= impulse * ny
+	b.vx += impulse * nx
+	b.vy += impulse * ny
+}
+
+// clink plays a bounce sound, at most once per money_bounce_sound_interval so a shower doesn't drown everything out
+func clink(speed float64) {
+	if !cfg.MoneyIsBounceSoundEnabled || speed < bounceSoundSpeed {
+		return
+	}
+	if time.Since(lastClink) < cfg.MoneyBounceSoundInterval {
+		return
+	}
+	lastClink = time.Now()
+	sound.PlayBounceRandom()
+}
+
+// collect adds a sprinkle's amount to its counter
+func collect(currency library.Misc, amount int) {
+	switch currency {
+	case library.MiscPlatinum:
+		platinumBuffer += amount
+	case library.MiscGold:
+		goldBuffer += amount
+	case library.MiscSilver:
+		silverBuffer += amount
+	case library.MiscCopper:
+		copperBuffer += amount
+	default:
+		otherBuffer[currency] += amount
+	}
+	upgradeTimer = time.Now().Add(time.Second * 3)
+}
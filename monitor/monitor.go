@@ -1,102 +1,87 @@
 package monitor
 
-/*
 import (
 	"fmt"
-	"syscall"
+	"image"
 
-	"golang.org/x/sys/windows"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xackery/critsprinkler/util"
 )
 
-// intersectwalk.Rectangle calculates the intersection of two walk.Rectangles.
-func intersectRectangle(r1, r2 walk.Rectangle) (walk.Rectangle, bool) {
-	intersect := walk.Rectangle{
-		Left:   max(r1.Left, r2.Left),
-		Top:    max(r1.Top, r2.Top),
-		Right:  min(r1.Right, r2.Right),
-		Bottom: min(r1.Bottom, r2.Bottom),
-	}
-	if intersect.Right > intersect.Left && intersect.Bottom > intersect.Top {
-		return intersect, true
-	}
-	return walk.Rectangle{}, false
+// entry pairs a monitor with a name that stays unique when two monitors share a model name
+type entry struct {
+	name    string
+	monitor *ebiten.MonitorType
 }
 
-// RectangleArea calculates the area of a walk.Rectangle.
-func RectangleArea(r walk.Rectangle) int32 {
-	return (r.Right - r.Left) * (r.Bottom - r.Top)
+func entries() []entry {
+	out := []entry{}
+	seen := map[string]int{}
+	for _, m := range ebiten.AppendMonitors(nil) {
+		name := m.Name()
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s #%d", name, seen[name])
+		}
+		out = append(out, entry{name: name, monitor: m})
+	}
+	return out
 }
 
-// max returns the maximum of two int32 values.
-func max(a, b int32) int32 {
-	if a > b {
-		return a
+// Names returns the names of every connected monitor
+func Names() []string {
+	names := []string{}
+	for _, e := range entries() {
+		names = append(names, e.name)
 	}
-	return b
+	return names
 }
 
-// min returns the minimum of two int32 values.
-func min(a, b int32) int32 {
-	if a < b {
-		return a
+// ByName returns a connected monitor by name, or nil if it is gone
+func ByName(name string) *ebiten.MonitorType {
+	for _, e := range entries() {
+		if e.name == name {
+			return e.monitor
+		}
 	}
-	return b
+	return nil
 }
 
-// MonitorFromWindow returns the monitor index that the window is primarily on.
-func MonitorFromWindow(hwnd windows.HWND) (int, error) {
-	// Get the window walk.Rectangleangle
-	var windowwalk.Rectangle windows.walk.Rectangle
-	if !win.GetWindowwalk.Rectangle(hwnd, &windowwalk.Rectangle) {
-		return -1, fmt.Errorf("failed to get window walk.Rectangle")
+// Current returns the name of the monitor the main window is on
+func Current() string {
+	current := ebiten.Monitor()
+	for _, e := range entries() {
+		if e.monitor == current {
+			return e.name
+		}
 	}
+	return ""
+}
 
-	// Prepare data for enumeration
-	type monitorInfo struct {
-		index int
-		walk.Rectangle  walk.Rectangle
+// Restore moves the main window to the named monitor.
+// It returns false if the monitor is no longer connected, the window then stays on the current monitor
+func Restore(name string) bool {
+	if name == "" {
+		return false
 	}
-	monitors := []monitorInfo{}
-	callback := syscall.NewCallback(func(hMonitor win.HMONITOR, hdcMonitor win.HDC, lprcMonitor *win.walk.Rectangle, dwData win.LPARAM) win.BOOL {
-		monitors = append(monitors, monitorInfo{
-			index: len(monitors),
-			walk.Rectangle: walk.Rectangle{
-				Left:   lprcMonitor.Left,
-				Top:    lprcMonitor.Top,
-				Right:  lprcMonitor.Right,
-				Bottom: lprcMonitor.Bottom,
-			},
-		})
-		return 1 // Continue enumeration
-	})
-
-	// Enumerate monitors
-	if !win.EnumDisplayMonitors(0, nil, callback, 0) {
-		return -1, fmt.Errorf("failed to enumerate monitors")
+	m := ByName(name)
+	if m == nil {
+		return false
 	}
+	ebiten.SetMonitor(m)
+	return true
+}
 
-	// Find the monitor with the largest intersection
-	var bestMonitor int = -1
-	var maxArea int32 = 0
-	windowwalk.Rectangle := walk.Rectangle{
-		Left:   windowwalk.Rectangle.Left,
-		Top:    windowwalk.Rectangle.Top,
-		Right:  windowwalk.Rectangle.Right,
-		Bottom: windowwalk.Rectangle.Bottom,
+// Clamp keeps rect, relative to the current monitor, fully on that monitor
+func Clamp(rect image.Rectangle) image.Rectangle {
+	w, h := ebiten.Monitor().Size()
+	if rect.Dx() > w {
+		rect.Max.X = rect.Min.X + w
 	}
-	for _, monitor := range monitors {
-		if intersect, ok := intersectwalk.Rectangle(windowwalk.Rectangle, monitor.walk.Rectangle); ok {
-			area := walk.RectangleArea(intersect)
-			if area > maxArea {
-				maxArea = area
-				bestMonitor = monitor.index
-			}
-		}
-	}
-
-	if bestMonitor == -1 {
-		return -1, fmt.Errorf("window is not primarily on any monitor")
+	if rect.Dy() > h {
+		rect.Max.Y = rect.Min.Y + h
 	}
-	return bestMonitor, nil
+	x := util.ClampInt(rect.Min.X, 0, w-rect.Dx())
+	y := util.ClampInt(rect.Min.Y, 0, h-rect.Dy())
+	return rect.Add(image.Point{x - rect.Min.X, y - rect.Min.Y})
 }
-*/
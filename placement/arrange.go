@@ -0,0 +1,402 @@
+package placement
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xackery/critsprinkler/common"
+)
+
+// Arrangement is a preset layout for every open placement
+type Arrangement int
+
+const (
+	// ArrangementRadial rings placements around the center of the overlay
+	ArrangementRadial Arrangement = iota
+	// ArrangementColumns puts incoming placements in a left column and outgoing in a right column
+	ArrangementColumns
+	// ArrangementSplit puts outgoing placements along the top and incoming along the bottom
+	ArrangementSplit
+	ArrangementMax
+)
+
+func (e Arrangement) String() string {
+	switch e {
+	case ArrangementRadial:
+		return "Radial"
+	case ArrangementColumns:
+		return "Columns"
+	case ArrangementSplit:
+		return "Top/Bottom Split"
+	}
+	return "unknown"
+}
+
+const (
+	// cells are this size when the overlay has room, and shrink to fit when it doesn't
+	arrangeCellWidth  = 220
+	arrangeCellHeight = 110
+	arrangeGap        = 10
+	arrangeMargin     = 20
+	// windows without popups, e.g. money and experience, can't be sized smaller than this
+	arrangeWindowMinWidth  = 100
+	arrangeWindowMinHeight = 80
+)
+
+// arrangeItem is an open window being arranged
+type arrangeItem struct {
+	placement  *common.Placement
+	min        image.Point // the smallest the window may be sized
+	isPopup    bool        // money and experience windows have no popups, so no direction
+	isIncoming bool
+}
+
+// arrangeSlot is where an item goes and which way its popups move
+type arrangeSlot struct {
+	cell      image.Rectangle
+	direction common.Direction
+}
+
+// Arrange moves every open placement, and the windows passed in such as money and experience, into a preset layout
+// so none overlap. Popup directions point away from the others, and placements of a group sit together sharing one.
+// If the preset doesn't fit the overlay at the windows' minimum sizes, a plain grid is used
+func Arrange(arrangement Arrangement, windows ...*common.Placement) {
+	w, h := ebiten.WindowSize()
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	items := arrangeItems(windows)
+	if len(items) == 0 {
+		return
+	}
+
+	var slots []arrangeSlot
+	ok := false
+	switch arrangement {
+	case ArrangementRadial:
+		slots, ok = layoutRadial(items, w, h)
+	case ArrangementColumns:
+		slots, ok = layoutColumns(items, w, h)
+	case ArrangementSplit:
+		slots, ok = layoutSplit(items, w, h)
+	}
+	if !ok {
+		slots = layoutGrid(items, w, h)
+	}
+
+	groupDirections := map[common.PlacementGroup]common.Direction{}
+	for i, item := range items {
+		direction := slots[i].direction
+		placement := item.placement
+		if item.isPopup && placement.Group != common.PlacementGroupNone && !placement.IsOverridden(common.LinkedSettingDirection) {
+			// the first member of a group picks the direction for all of them
+			groupDirection, ok := groupDirections[placement.Group]
+			if ok {
+				direction = groupDirection
+			}
+			groupDirections[placement.Group] = direction
+		}
+		arrangeTo(item, slots[i].cell, direction, w, h)
+	}
+	for _, item := range items {
+		if item.isPopup {
+			share(item.placement, common.LinkedSettingDirection)
+		}
+	}
+}
+
+// arrangeItems returns the open placements with members of a group next to each other, followed by the open windows
+func arrangeItems(windows []*common.Placement) []arrangeItem {
+	open := []*common.Placement{}
+	for _, placement := range placements {
+		if placement.Window == nil {
+			continue
+		}
+		open = append(open, placement)
+	}
+
+	// a group sorts where its first member would, so the members land in neighbouring cells
+	groupOrder := map[common.PlacementGroup]common.PopupCategory{}
+	for _, placement := range open {
+		if placement.Group == common.PlacementGroupNone {
+			continue
+		}
+		order, ok := groupOrder[placement.Group]
+		if !ok || placement.Category < order {
+			groupOrder[placement.Group] = placement.Category
+		}
+	}
+	orderOf := func(placement *common.Placement) common.PopupCategory {
+		if placement.Group == common.PlacementGroupNone {
+			return placement.Category
+		}
+		return groupOrder[placement.Group]
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if orderOf(open[i]) != orderOf(open[j]) {
+			return orderOf(open[i]) < orderOf(open[j])
+		}
+		return open[i].Category < open[j].Category
+	})
+
+	items := []arrangeItem{}
+	for _, placement := range open {
+		minWidth, minHeight := placement.Category.MinSize()
+		items = append(items, arrangeItem{
+			placement:  placement,
+			min:        image.Point{minWidth, minHeight},
+			isPopup:    true,
+			isIncoming: common.IsIncoming(placement.Category),
+		})
+	}
+	for _, window := range windows {
+		if window == nil || window.Window == nil {
+			continue
+		}
+		items = append(items, arrangeItem{
+			placement: window,
+			min:       image.Point{arrangeWindowMinWidth, arrangeWindowMinHeight},
+		})
+	}
+	return items
+}
+
+// arrangeTo moves a window to a cell and remembers where it is
+func arrangeTo(item arrangeItem, cell image.Rectangle, direction common.Direction, w, h int) {
+	rect := image.Rect(cell.Min.X, cell.Min.Y, cell.Max.X-arrangeGap, cell.Max.Y-arrangeGap)
+	rect = common.ClampRect(rect, w, h)
+	placement := item.placement
+	if item.isPopup {
+		placement.Direction = direction
+	}
+	placement.Window.SetLocation(rect)
+	*placement.WindowRect = rect
+	placement.Capture(rect, w, h)
+}
+
+// fitCell returns the cell size that fits cols by rows cells inside the margins, no larger than the preferred size
+func fitCell(cols, rows, w, h int) image.Point {
+	return image.Point{
+		min(arrangeCellWidth, (w-2*arrangeMargin)/max(1, cols)),
+		min(arrangeCellHeight, (h-2*arrangeMargin)/max(1, rows)),
+	}
+}
+
+// isFitting returns true if every item's window fits in a cell of size
+func isFitting(items []arrangeItem, size image.Point) bool {
+	for _, item := range items {
+		if size.X-arrangeGap < item.min.X || size.Y-arrangeGap < item.min.Y {
+			return false
+		}
+	}
+	return true
+}
+
+// layoutRadial fills rings of cells around the center cell, clockwise from the top.
+// Cells shrink so the outer ring stays on screen
+func layoutRadial(items []arrangeItem, w, h int) ([]arrangeSlot, bool) {
+	cells := []image.Point{}
+	rings := 0
+	for len(cells) < len(items) {
+		rings++
+		cells = append(cells, ringCells(rings)...)
+	}
+	span := 2*rings + 1
+	size := fitCell(span, span, w, h)
+	if !isFitting(items, size) {
+		return nil, false
+	}
+
+	center := image.Point{w / 2, h / 2}
+	slots := []arrangeSlot{}
+	for i := range items {
+		cell := cells[i]
+		min := center.Add(image.Point{
+			cell.X*size.X - size.X/2,
+			cell.Y*size.Y - size.Y/2,
+		})
+		slots = append(slots, arrangeSlot{image.Rectangle{min, min.Add(size)}, directionOf(cell)})
+	}
+	return slots, true
+}
+
+// ringCells returns the grid offsets that are ring steps away from the center, sorted clockwise from the top
+func ringCells(ring int) []image.Point {
+	cells := []image.Point{}
+	for y := -ring; y <= ring; y++ {
+		for x := -ring; x <= ring; x++ {
+			if max(abs(x), abs(y)) != ring {
+				continue
+			}
+			cells = append(cells, image.Point{x, y})
+		}
+	}
+	angle := func(p image.Point) float64 {
+		a := math.Atan2(float64(p.X), float64(-p.Y))
+		if a < 0 {
+			a += 2 * math.Pi
+		}
+		return a
+	}
+	sort.Slice(cells, func(i, j int) bool { return angle(cells[i]) < angle(cells[j]) })
+	return cells
+}
+
+// directionOf returns the direction pointing from the center towards a grid offset
+func directionOf(cell image.Point) common.Direction {
+	switch {
+	case cell.X == 0 && cell.Y < 0:
+		return common.DirectionUp
+	case cell.X > 0 && cell.Y < 0:
+		return common.DirectionUpRight
+	case cell.X > 0 && cell.Y == 0:
+		return common.DirectionRight
+	case cell.X > 0 && cell.Y > 0:
+		return common.DirectionDownRight
+	case cell.X == 0 && cell.Y > 0:
+		return common.DirectionDown
+	case cell.X < 0 && cell.Y > 0:
+		return common.DirectionDownLeft
+	case cell.X < 0 && cell.Y == 0:
+		return common.DirectionLeft
+	case cell.X < 0 && cell.Y < 0:
+		return common.DirectionUpLeft
+	}
+	return common.DirectionUp
+}
+
+// layoutColumns stacks incoming placements down the left edge and outgoing down the right edge,
+// wrapping into another column towards the center when one is full. Columns narrow to fit the overlay
+func layoutColumns(items []arrangeItem, w, h int) ([]arrangeSlot, bool) {
+	perColumn := max(1, (h-2*arrangeMargin)/arrangeCellHeight)
+	incoming, outgoing := splitIncoming(items)
+	columns := ceilDiv(len(incoming), perColumn) + ceilDiv(len(outgoing), perColumn)
+	size := fitCell(columns, perColumn, w, h)
+	if !isFitting(items, size) {
+		return nil, false
+	}
+
+	slots := make([]arrangeSlot, len(items))
+	for i, index := range incoming {
+		x := arrangeMargin + i/perColumn*size.X
+		y := arrangeMargin + i%perColumn*size.Y
+		slots[index] = arrangeSlot{image.Rect(x, y, x+size.X, y+size.Y), common.DirectionUpLeft}
+	}
+	for i, index := range outgoing {
+		x := w - arrangeMargin + arrangeGap - (i/perColumn+1)*size.X
+		y := arrangeMargin + i%perColumn*size.Y
+		slots[index] = arrangeSlot{image.Rect(x, y, x+size.X, y+size.Y), common.DirectionUpRight}
+	}
+	return slots, true
+}
+
+// layoutSplit lines outgoing placements up along the top edge and incoming along the bottom edge,
+// wrapping into another row towards the center when one is full. Rows flatten to fit the overlay
+func layoutSplit(items []arrangeItem, w, h int) ([]arrangeSlot, bool) {
+	perRow := max(1, (w-2*arrangeMargin)/arrangeCellWidth)
+	incoming, outgoing := splitIncoming(items)
+	rows := ceilDiv(len(incoming), perRow) + ceilDiv(len(outgoing), perRow)
+	size := fitCell(perRow, rows, w, h)
+	if !isFitting(items, size) {
+		return nil, false
+	}
+
+	rowStart := func(i int, count int) int {
+		inRow := min(perRow, count-i/perRow*perRow)
+		return (w - inRow*size.X + arrangeGap) / 2
+	}
+	slots := make([]arrangeSlot, len(items))
+	for i, index := range outgoing {
+		x := rowStart(i, len(outgoing)) + i%perRow*size.X
+		y := arrangeMargin + i/perRow*size.Y
+		slots[index] = arrangeSlot{image.Rect(x, y, x+size.X, y+size.Y), common.DirectionUp}
+	}
+	for i, index := range incoming {
+		x := rowStart(i, len(incoming)) + i%perRow*size.X
+		y := h - arrangeMargin + arrangeGap - (i/perRow+1)*size.Y
+		slots[index] = arrangeSlot{image.Rect(x, y, x+size.X, y+size.Y), common.DirectionDown}
+	}
+	return slots, true
+}
+
+// layoutGrid spreads items over the grid with the largest cells, for when a preset doesn't fit.
+// Directions point away from the middle of the grid
+func layoutGrid(items []arrangeItem, w, h int) []arrangeSlot {
+	cols := 1
+	best := image.Point{}
+	for c := 1; c <= len(items); c++ {
+		size := fitCell(c, ceilDiv(len(items), c), w, h)
+		// the grid with the most room for the tightest of width and height wins
+		scale := min(float64(size.X)/arrangeCellWidth, float64(size.Y)/arrangeCellHeight)
+		bestScale := min(float64(best.X)/arrangeCellWidth, float64(best.Y)/arrangeCellHeight)
+		if scale > bestScale {
+			cols = c
+			best = size
+		}
+	}
+	rows := ceilDiv(len(items), cols)
+
+	left := (w - cols*best.X + arrangeGap) / 2
+	top := (h - rows*best.Y + arrangeGap) / 2
+	slots := []arrangeSlot{}
+	for i := range items {
+		col, row := i%cols, i/cols
+		x := left + col*best.X
+		y := top + row*best.Y
+		offset := image.Point{sign(2*col - (cols - 1)), sign(2*row - (rows - 1))}
+		slots = append(slots, arrangeSlot{image.Rect(x, y, x+best.X, y+best.Y), directionOf(offset)})
+	}
+	return slots
+}
+
+// splitIncoming returns the indexes of items for damage and heals landing on the player, and of those the player does.
+// Windows without popups go to whichever side has fewer
+func splitIncoming(items []arrangeItem) ([]int, []int) {
+	incoming := []int{}
+	outgoing := []int{}
+	for i, item := range items {
+		if !item.isPopup {
+			continue
+		}
+		if item.isIncoming {
+			incoming = append(incoming, i)
+			continue
+		}
+		outgoing = append(outgoing, i)
+	}
+	for i, item := range items {
+		if item.isPopup {
+			continue
+		}
+		if len(incoming) < len(outgoing) {
+			incoming = append(incoming, i)
+			continue
+		}
+		outgoing = append(outgoing, i)
+	}
+	return incoming, outgoing
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+func sign(value int) int {
+	switch {
+	case value < 0:
+		return -1
+	case value > 0:
+		return 1
+	}
+	return 0
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
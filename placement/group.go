@@ -0,0 +1,67 @@
+package placement
+
+import (
+	"github.com/xackery/critsprinkler/common"
+)
+
+// share copies a linked setting from a placement to every other placement in its group that does not override it
+func share(placement *common.Placement, setting common.LinkedSetting) {
+	if placement.Group == common.PlacementGroupNone || placement.IsOverridden(setting) {
+		return
+	}
+	for _, member := range placements {
+		if member == placement || member.Group != placement.Group || member.IsOverridden(setting) {
+			continue
+		}
+		member.CopySetting(placement, setting)
+	}
+}
+
+// link moves a placement to a group, taking on the settings the group already shares
+func link(placement *common.Placement, group common.PlacementGroup) {
+	placement.Group = group
+	if group == common.PlacementGroupNone {
+		return
+	}
+	for _, member := range placements {
+		if member == placement || member.Group != group {
+			continue
+		}
+		for setting := common.LinkedSetting(0); setting < common.LinkedSettingMax; setting++ {
+			if placement.IsOverridden(setting) || member.IsOverridden(setting) {
+				continue
+			}
+			placement.CopySetting(member, setting)
+		}
+		return
+	}
+}
+
+// nextGroup returns the next group after the current one that a placement's category may be linked to
+func nextGroup(placement *common.Placement) common.PlacementGroup {
+	group := placement.Group
+	for {
+		group = (group + 1) % common.PlacementGroupMax
+		if group == common.PlacementGroupNone || group.Contains(placement.Category) {
+			return group
+		}
+	}
+}
+
+// linkMembers links every category the placement's group contains to it, sharing the placement's settings with them.
+// Custom groups are picked one placement at a time, so nothing is added to them
+func linkMembers(placement *common.Placement) {
+	group := placement.Group
+	if group == common.PlacementGroupNone || group == common.PlacementGroupCustom {
+		return
+	}
+	for _, member := range placements {
+		if member == placement || !group.Contains(member.Category) {
+			continue
+		}
+		member.Group = group
+	}
+	for setting := common.LinkedSetting(0); setting < common.LinkedSettingMax; setting++ {
+		share(placement, setting)
+	}
+}
@@ -0,0 +1,48 @@
+package placement
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/xackery/critsprinkler/common"
+)
+
+func TestShareColor(t *testing.T) {
+	previous := placements
+	placements = make(map[common.PopupCategory]*common.Placement)
+	t.Cleanup(func() { placements = previous })
+	for category := common.PopupCategory(0); category < common.PopupCategoryMax; category++ {
+		placements[category] = &common.Placement{Category: category, FontColor: color.RGBA{255, 255, 255, 255}}
+	}
+
+	// linking the melee crit placement to crits links every crit category to it
+	source := ByCategory(common.PopupCategoryMeleeCritOut)
+	source.Group = common.PlacementGroupCrits
+	linkMembers(source)
+	overridden := ByCategory(common.PopupCategorySpellCritIn)
+	overridden.SetOverridden(common.LinkedSettingColor, true)
+
+	// as done by the color option of the settings panel
+	red := color.RGBA{255, 0, 0, 255}
+	source.FontColor = red
+	share(source, common.LinkedSettingColor)
+
+	// popups take their color from the placement of their category, see popup.spawn
+	for category := common.PopupCategory(0); category < common.PopupCategoryMax; category++ {
+		got := ByCategory(category).FontColor
+		want := color.RGBA{255, 255, 255, 255}
+		if common.PlacementGroupCrits.Contains(category) && category != common.PopupCategorySpellCritIn {
+			want = red
+		}
+		if got != want {
+			t.Fatalf("%s: got color %v, want %v", category, got, want)
+		}
+	}
+
+	// a placement linked later takes on the color the group shares
+	overridden.SetOverridden(common.LinkedSettingColor, false)
+	link(overridden, common.PlacementGroupCrits)
+	if overridden.FontColor != red {
+		t.Fatalf("linked later: got color %v, want %v", overridden.FontColor, red)
+	}
+}
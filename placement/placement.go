@@ -16,12 +16,15 @@ import (
 
 var (
 	ui         *ebitenui.UI
+	cfg        *config.CritSprinklerConfiguration
+	isSized    bool // set once the overlay size is known
 	placements = make(map[common.PopupCategory]*common.Placement)
 )
 
-func New(eui *ebitenui.UI, cfg *config.CritSprinklerConfiguration) error {
+func New(eui *ebitenui.UI, ecfg *config.CritSprinklerConfiguration) error {
 	var err error
 	ui = eui
+	cfg = ecfg
 
 	placements[common.PopupCategoryMeleeHitOut] = &cfg.MeleeHitOut
 	placements[common.PopupCategoryMeleeHitIn] = &cfg.MeleeHitIn
@@ -49,7 +52,11 @@ func New(eui *ebitenui.UI, cfg *config.CritSprinklerConfiguration) error {
 	for category := range placements {
 		placement := placements[category]
 		placement.Category = category
-		placement.FontFace, err = library.FontByKey(library.Font(library.FontPopupNotoSansBold42))
+		if placement.Relative.IsZero() {
+			// older configs only have pixels, measured on the overlay size they were saved with
+			placement.Capture(*placement.WindowRect, cfg.MainWindow.Dx(), cfg.MainWindow.Dy())
+		}
+		placement.FontFace, err = library.FontByKey(popupFont(placement.Font))
 		if err != nil {
 			return fmt.Errorf("fontByKey: %w", err)
 		}
@@ -75,6 +82,7 @@ func SetEditMode(ui *ebitenui.UI, editMode bool) {
 	state := widget.Visibility_Show
 	if !editMode {
 		state = widget.Visibility_Hide
+		CloseSettings()
 		for _, placement := range placements {
 			if placement.IsVisible == 0 {
 				continue
@@ -155,7 +163,10 @@ func Open(category common.PopupCategory) error {
 			Disabled: util.HexToColor("5A7A91FF"),
 		}),
 		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-
+			err := OpenSettings(placement.Category)
+			if err != nil {
+				fmt.Println("open settings:", err)
+			}
 		}),
 		widget.ButtonOpts.TabOrder(99),
 	))
@@ -190,16 +201,20 @@ func Open(category common.PopupCategory) error {
 	)
 	placement.PanelContainer = c
 
+	minWidth, minHeight := category.MinSize()
 	placement.Window = widget.NewWindow(
 		//widget.WindowOpts.Modal(),
 		widget.WindowOpts.Contents(c),
 		widget.WindowOpts.TitleBar(placement.TitleBar, 16),
 		widget.WindowOpts.Draggable(),
 		widget.WindowOpts.Resizeable(),
-		widget.WindowOpts.MinSize(100, 80),
+		widget.WindowOpts.MinSize(minWidth, minHeight),
 		//widget.WindowOpts.MaxSize(300, 1000),
 		widget.WindowOpts.MoveHandler(func(args *widget.WindowChangedEventArgs) {
-			OnResize()
+			onMoved(placement)
+		}),
+		widget.WindowOpts.ResizeHandler(func(args *widget.WindowChangedEventArgs) {
+			onMoved(placement)
 		}),
 	)
 	//windowSize := input.GetWindowSize()
@@ -208,6 +223,10 @@ func Open(category common.PopupCategory) error {
 	//r = r.Add(image.Point{windowSize.X / 4 / 2, windowSize.Y * 2 / 3 / 5})
 
 	//window.SetLocation(r)
+	if isSized {
+		w, h := ebiten.WindowSize()
+		*placement.WindowRect = common.ClampRect(placement.Resolve(w, h), w, h)
+	}
 	placement.Window.SetLocation(*placement.WindowRect)
 
 	_ = ui.AddWindow(placement.Window)
@@ -228,6 +247,39 @@ func Close(category common.PopupCategory) error {
 	return nil
 }
 
+// Reload reopens every placement after its settings were replaced, e.g. by a layout profile
+func Reload() error {
+	CloseSettings()
+	for category, placement := range placements {
+		isVisible := placement.IsVisible
+		if placement.Window != nil {
+			placement.Window.Close()
+			placement.Window = nil
+		}
+		placement.IsVisible = isVisible
+		if isVisible == 0 {
+			continue
+		}
+		err := Open(category)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", category.String(), err)
+		}
+	}
+	return nil
+}
+
+// ConfigUpdate applies reloaded placement settings, reopening every placement with its new fonts
+func ConfigUpdate() error {
+	for _, placement := range placements {
+		face, err := library.FontByKey(popupFont(placement.Font))
+		if err != nil {
+			return fmt.Errorf("fontByKey: %w", err)
+		}
+		placement.FontFace = face
+	}
+	return Reload()
+}
+
 // Toggle opens or closes the window
 func Toggle(category common.PopupCategory) error {
 	window := placements[category].Window
@@ -238,37 +290,37 @@ func Toggle(category common.PopupCategory) error {
 }
 
 func Update(isEditMode bool) {
+	if !isEditMode {
+		return
+	}
+	updateGuides()
 }
 
+// OnResize places every open placement for the current overlay size
 func OnResize() {
+	w, h := ebiten.WindowSize()
+	isSized = true
 	for _, placement := range placements {
-		w, h := ebiten.WindowSize()
 		if placement.Window == nil {
 			continue
 		}
 
-		fmt.Println("onresize", placement.Category)
-		rect := placement.Window.GetContainer().GetWidget().Rect
-		originalRect := rect
-
-		newMinX := util.ClampInt(rect.Min.X, 0, w-rect.Dx())
-		newMaxX := newMinX + rect.Dx()
-
-		newMinY := util.ClampInt(rect.Min.Y, 0, h-rect.Dy())
-		newMaxY := newMinY + rect.Dy()
-
-		newRect := rect
-		newRect.Min.X = newMinX
-		newRect.Max.X = newMaxX
-		newRect.Min.Y = newMinY
-		newRect.Max.Y = newMaxY
-
-		fmt.Println("going from", originalRect, "to", newRect)
-		placement.Window.SetLocation(newRect)
-		*placement.WindowRect = newRect
+		rect := common.ClampRect(placement.Resolve(w, h), w, h)
+		placement.Window.SetLocation(rect)
+		*placement.WindowRect = placement.Window.GetContainer().GetWidget().Rect
 	}
 }
 
+// onMoved keeps a placement on screen after it was dragged or resized and remembers where it is
+func onMoved(placement *common.Placement) {
+	snap(placement)
+	w, h := ebiten.WindowSize()
+	rect := common.ClampRect(placement.Window.GetContainer().GetWidget().Rect, w, h)
+	placement.Window.SetLocation(rect)
+	*placement.WindowRect = rect
+	placement.Capture(rect, w, h)
+}
+
 func buttonImage(spellIconID int) *widget.ButtonImage {
 	nineSlice := library.SpellByIDNineSlice(spellIconID)
 	if nineSlice == nil {
@@ -281,6 +333,19 @@ func buttonImage(spellIconID int) *widget.ButtonImage {
 	}
 }
 
+// popupFont returns the library font a placement's font setting draws popups with
+func popupFont(font common.Font) library.Font {
+	switch font {
+	case common.FontNotoSansRegular42:
+		return library.FontPopupNotoSansRegular42
+	case common.FontNotoSansRegular36:
+		return library.FontPopupNotoSansRegular36
+	case common.FontNotoSansBold36:
+		return library.FontPopupNotoSansBold36
+	}
+	return library.FontPopupNotoSansBold42
+}
+
 func ByCategory(category common.PopupCategory) *common.Placement {
 	return placements[category]
 }
@@ -0,0 +1,421 @@
+package placement
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	eimage "github.com/ebitenui/ebitenui/image"
+	"github.com/ebitenui/ebitenui/widget"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/xackery/critsprinkler/common"
+	"github.com/xackery/critsprinkler/library"
+	"github.com/xackery/critsprinkler/util"
+)
+
+const (
+	// the panel is this size when the overlay has room, otherwise it fits the overlay and scrolls
+	settingsWidth  = 840
+	settingsHeight = 720
+	// below this overlay width the two columns of options are stacked
+	settingsTwoColumnWidth = 760
+	settingsScrollStep     = 40 // pixels moved per mouse wheel step
+)
+
+var (
+	settingsWindow    *widget.Window
+	isSettingsChanged bool // set when an option was changed since the panel opened
+)
+
+// OpenSettings shows every option of a placement along with options shared by all popups, replacing any open panel.
+// Changes apply to popups right away and are saved when the panel closes, if there were any
+func OpenSettings(category common.PopupCategory) error {
+	placement := placements[category]
+	if placement == nil {
+		return fmt.Errorf("no placement for %s", category.String())
+	}
+	CloseSettings()
+
+	panelNineSlice, err := library.NinesliceByKey(library.NineSlicePanelIdle)
+	if err != nil {
+		return fmt.Errorf("ninesliceByKey: %w", err)
+	}
+	titleNineSlice, err := library.NinesliceByKey(library.NineSliceTitlebarIdle)
+	if err != nil {
+		return fmt.Errorf("ninesliceByKey: %w", err)
+	}
+	buttonCloseImage, err := library.ButtonImageByKey(library.ButtonImageClose)
+	if err != nil {
+		return fmt.Errorf("buttonImageByKey: %w", err)
+	}
+	buttonImage, err := library.ButtonImageByKey(library.ButtonImageDefault)
+	if err != nil {
+		return fmt.Errorf("buttonImageByKey: %w", err)
+	}
+	buttonCheckboxImage, err := library.ButtonImageByKey(library.ButtonImageCheckbox)
+	if err != nil {
+		return fmt.Errorf("buttonImageByKey: %w", err)
+	}
+	textInputImage, err := library.TextInputImage()
+	if err != nil {
+		return fmt.Errorf("textInputImage: %w", err)
+	}
+	checkboxImage, err := library.CheckboxGraphicImage()
+	if err != nil {
+		return fmt.Errorf("checkboxGraphicImage: %w", err)
+	}
+
+	textColor := util.HexToColor("dFF4FFFF")
+	disabledColor := util.HexToColor("5A7A91FF")
+
+	titleBar := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(titleNineSlice),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(2),
+			widget.GridLayoutOpts.Stretch([]bool{true, false}, []bool{true}),
+			widget.GridLayoutOpts.Padding(widget.Insets{Left: 10, Right: 5}),
+		)))
+	titleBar.AddChild(widget.NewText(
+		widget.TextOpts.Text(placement.Category.String()+" Options", placement.TitleFontFace, textColor),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+	))
+	titleBar.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonCloseImage),
+		widget.ButtonOpts.TextPadding(widget.Insets{Left: 16, Right: 16}),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			CloseSettings()
+		}),
+	))
+
+	column := func() *widget.Container {
+		return widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewGridLayout(
+				widget.GridLayoutOpts.Columns(2),
+				widget.GridLayoutOpts.Stretch([]bool{false, true}, nil),
+				widget.GridLayoutOpts.Spacing(10, 6),
+			)),
+		)
+	}
+	left := column()
+	right := column()
+
+	w, h := ebiten.WindowSize()
+	panelWidth := min(settingsWidth, w)
+	panelHeight := min(settingsHeight, h)
+	columns := []bool{true, true}
+	if w < settingsTwoColumnWidth {
+		columns = []bool{true}
+	}
+	c := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(len(columns)),
+			widget.GridLayoutOpts.Stretch(columns, nil),
+			widget.GridLayoutOpts.Spacing(20, 10),
+			widget.GridLayoutOpts.Padding(widget.Insets{Left: 10, Right: 10, Top: 10, Bottom: 10}),
+		)),
+	)
+	c.AddChild(left)
+	c.AddChild(right)
+
+	addLabel := func(c *widget.Container, label string) {
+		c.AddChild(widget.NewText(
+			widget.TextOpts.Text(label, placement.TitleFontFace, textColor),
+			widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+		))
+	}
+
+	addHeader := func(c *widget.Container, label string) {
+		addLabel(c, label)
+		c.AddChild(widget.NewContainer())
+	}
+
+	addInput := func(c *widget.Container, label string, value string, placeholder string, onChange func(value string)) {
+		addLabel(c, label)
+		input := widget.NewTextInput(
+			widget.TextInputOpts.Image(textInputImage),
+			widget.TextInputOpts.Face(placement.TitleFontFace),
+			widget.TextInputOpts.Color(&widget.TextInputColor{
+				Idle:          textColor,
+				Disabled:      disabledColor,
+				Caret:         textColor,
+				DisabledCaret: disabledColor,
+			}),
+			widget.TextInputOpts.Padding(widget.Insets{Left: 8, Right: 8, Top: 4, Bottom: 4}),
+			widget.TextInputOpts.CaretOpts(widget.CaretOpts.Size(placement.TitleFontFace, 2)),
+			widget.TextInputOpts.Placeholder(placeholder),
+			widget.TextInputOpts.ChangedHandler(func(args *widget.TextInputChangedEventArgs) {
+				isSettingsChanged = true
+				onChange(args.InputText)
+			}),
+		)
+		input.SetText(value)
+		c.AddChild(input)
+	}
+
+	addCheckbox := func(c *widget.Container, label string, value bool, onChange func(value bool)) {
+		state := widget.WidgetUnchecked
+		if value {
+			state = widget.WidgetChecked
+		}
+		addLabel(c, label)
+		c.AddChild(widget.NewCheckbox(
+			widget.CheckboxOpts.ButtonOpts(widget.ButtonOpts.Image(buttonCheckboxImage)),
+			widget.CheckboxOpts.Image(checkboxImage),
+			widget.CheckboxOpts.InitialState(state),
+			widget.CheckboxOpts.StateChangedHandler(func(args *widget.CheckboxChangedEventArgs) {
+				isSettingsChanged = true
+				onChange(args.State == widget.WidgetChecked)
+			}),
+		))
+	}
+
+	addButton := func(c *widget.Container, label string, onClick func()) {
+		c.AddChild(widget.NewContainer())
+		c.AddChild(widget.NewButton(
+			widget.ButtonOpts.Image(buttonImage),
+			widget.ButtonOpts.Text(label, placement.TitleFontFace, &widget.ButtonTextColor{
+				Idle:     textColor,
+				Disabled: disabledColor,
+			}),
+			widget.ButtonOpts.TextPadding(widget.Insets{Left: 8, Right: 8, Top: 4, Bottom: 4}),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				isSettingsChanged = true
+				onClick()
+			}),
+		))
+	}
+
+	addCycle := func(c *widget.Container, label string, value func() string, onClick func()) {
+		addLabel(c, label)
+		var button *widget.Button
+		button = widget.NewButton(
+			widget.ButtonOpts.Image(buttonImage),
+			widget.ButtonOpts.Text(value(), placement.TitleFontFace, &widget.ButtonTextColor{
+				Idle:     textColor,
+				Disabled: disabledColor,
+			}),
+			widget.ButtonOpts.TextPadding(widget.Insets{Left: 8, Right: 8, Top: 4, Bottom: 4}),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				isSettingsChanged = true
+				onClick()
+				button.Text().Label = value()
+			}),
+		)
+		c.AddChild(button)
+	}
+
+	// milliseconds parses a duration typed in milliseconds, falling back when it is not a positive number
+	milliseconds := func(value string, fallback time.Duration) time.Duration {
+		val, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || val <= 0 {
+			return fallback
+		}
+		return time.Duration(val) * time.Millisecond
+	}
+
+	addHeader(left, "Display")
+	addCycle(left, "Direction", func() string { return placement.Direction.String() }, func() {
+		placement.Direction = (placement.Direction + 1) % common.DirectionMax
+		share(placement, common.LinkedSettingDirection)
+	})
+	addCycle(left, "Font", func() string { return placement.Font.String() }, func() {
+		placement.Font = (placement.Font + 1) % common.FontMax
+		face, err := library.FontByKey(popupFont(placement.Font))
+		if err != nil {
+			fmt.Println("fontByKey:", err)
+			return
+		}
+		placement.FontFace = face
+		share(placement, common.LinkedSettingFont)
+	})
+	addInput(left, "Color (RRGGBBAA)", hexColor(placement.FontColor), "FF00FFFF", func(value string) {
+		fontColor, ok := parseHexColor(value)
+		if !ok {
+			return
+		}
+		placement.FontColor = fontColor
+		share(placement, common.LinkedSettingColor)
+	})
+	addInput(left, "Duration (ms)", strconv.Itoa(int(placement.Duration.Milliseconds())), "4000", func(value string) {
+		placement.Duration = milliseconds(value, 4*time.Second)
+		share(placement, common.LinkedSettingDuration)
+	})
+	addCycle(left, "Anchor", func() string { return placement.Anchor.String() }, func() {
+		placement.Anchor = (placement.Anchor + 1) % common.AnchorMax
+		w, h := ebiten.WindowSize()
+		placement.Capture(*placement.WindowRect, w, h)
+	})
+	addCheckbox(left, "Tally", placement.IsTallyEnabled == 1, func(value bool) {
+		placement.IsTallyEnabled = 0
+		if value {
+			placement.IsTallyEnabled = 1
+		}
+	})
+	addCycle(left, "Tally By", func() string { return placement.TallyKey.String() }, func() {
+		placement.TallyKey = (placement.TallyKey + 1) % common.TallyKeyMax
+	})
+	addCheckbox(left, "Tally Label", placement.IsTallyLabeled, func(value bool) {
+		placement.IsTallyLabeled = value
+	})
+	addCheckbox(left, "Merge Flurries", placement.IsFlurryMerged, func(value bool) {
+		placement.IsFlurryMerged = value
+	})
+	addInput(left, "Flurry Window (ms)", strconv.Itoa(int(placement.FlurryWindow.Milliseconds())), "1000", func(value string) {
+		placement.FlurryWindow = milliseconds(value, time.Second)
+	})
+
+	addHeader(left, "Group")
+	addCycle(left, "Linked To", func() string { return placement.Group.String() }, func() {
+		link(placement, nextGroup(placement))
+		// reopen so the panel shows the settings taken from the group
+		err := OpenSettings(placement.Category)
+		if err != nil {
+			fmt.Println("open settings:", err)
+		}
+	})
+	addButton(left, "Link Every Member", func() {
+		linkMembers(placement)
+	})
+	for setting := common.LinkedSetting(0); setting < common.LinkedSettingMax; setting++ {
+		addCheckbox(left, "Own "+setting.String(), placement.IsOverridden(setting), func(value bool) {
+			placement.SetOverridden(setting, value)
+		})
+	}
+
+	addHeader(right, "Filter")
+	addInput(right, "Min Damage", strconv.Itoa(placement.MinDamage), "0", func(value string) {
+		val, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			val = 0
+		}
+		placement.MinDamage = val
+	})
+	addInput(right, "Include Names", strings.Join(placement.IncludeNames, ", "), "all", func(value string) {
+		placement.IncludeNames = util.SplitList(value)
+	})
+	addInput(right, "Exclude Names", strings.Join(placement.ExcludeNames, ", "), "none", func(value string) {
+		placement.ExcludeNames = util.SplitList(value)
+	})
+	addInput(right, "Include Spells", strings.Join(placement.IncludeSpells, ", "), "all", func(value string) {
+		placement.IncludeSpells = util.SplitList(value)
+	})
+	addInput(right, "Exclude Spells", strings.Join(placement.ExcludeSpells, ", "), "none", func(value string) {
+		placement.ExcludeSpells = util.SplitList(value)
+	})
+	addCheckbox(right, "Skip Pets", placement.IsPetSkipped, func(value bool) {
+		placement.IsPetSkipped = value
+	})
+	addCheckbox(right, "Skip Self", placement.IsSelfSkipped, func(value bool) {
+		placement.IsSelfSkipped = value
+	})
+
+	addHeader(right, "All Popups")
+	addInput(right, "Tally Duration (ms)", strconv.Itoa(int(cfg.PopupTallyDuration.Milliseconds())), "5000", func(value string) {
+		cfg.PopupTallyDuration = milliseconds(value, 5*time.Second)
+	})
+	addInput(right, "Max Per Placement", strconv.Itoa(cfg.PopupMaxPerPlacement), "12", func(value string) {
+		val, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || val <= 0 {
+			val = 12
+		}
+		cfg.PopupMaxPerPlacement = val
+	})
+	addCheckbox(right, "Merge Overflow", cfg.PopupIsOverflowMerged, func(value bool) {
+		cfg.PopupIsOverflowMerged = value
+	})
+	addCycle(right, "Number Separator", func() string { return cfg.NumberSeparator.String() }, func() {
+		cfg.NumberSeparator = (cfg.NumberSeparator + 1) % (util.NumberSeparatorSpace + 1)
+	})
+	addCycle(right, "Abbreviate", func() string { return cfg.NumberAbbreviation.String() }, func() {
+		cfg.NumberAbbreviation = (cfg.NumberAbbreviation + 1) % (util.NumberAbbreviationMillions + 1)
+	})
+	addInput(right, "Significant Digits", strconv.Itoa(int(cfg.NumberSignificantDigits)), "3", func(value string) {
+		val, err := strconv.Atoi(strings.TrimSpace(value))
+		digits := util.SignificantDigits(val)
+		if err != nil || !digits.IsValid() {
+			digits = 3
+		}
+		cfg.NumberSignificantDigits = digits
+	})
+
+	// the options scroll with the mouse wheel when the overlay is too small to show them all
+	scroll := widget.NewScrollContainer(
+		widget.ScrollContainerOpts.Content(c),
+		widget.ScrollContainerOpts.StretchContentWidth(),
+		widget.ScrollContainerOpts.Image(&widget.ScrollContainerImage{
+			Idle: eimage.NewNineSliceColor(color.Transparent),
+			Mask: eimage.NewNineSliceColor(color.White),
+		}),
+	)
+	scroll.GetWidget().ScrolledEvent.AddHandler(func(args any) {
+		a := args.(*widget.WidgetScrolledEventArgs)
+		hidden := scroll.ContentRect().Dy() - scroll.ViewRect().Dy()
+		if hidden <= 0 {
+			return
+		}
+		scroll.ScrollTop = math.Max(0, math.Min(1, scroll.ScrollTop-a.Y*settingsScrollStep/float64(hidden)))
+	})
+	contents := widget.NewContainer(
+		widget.ContainerOpts.BackgroundImage(panelNineSlice),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(1),
+			widget.GridLayoutOpts.Stretch([]bool{true}, []bool{true}),
+		)),
+	)
+	contents.AddChild(scroll)
+
+	settingsWindow = widget.NewWindow(
+		widget.WindowOpts.Contents(contents),
+		widget.WindowOpts.TitleBar(titleBar, 16),
+		widget.WindowOpts.Draggable(),
+		widget.WindowOpts.MinSize(panelWidth, panelHeight),
+	)
+
+	// open beside the placement, kept on screen
+	x := util.ClampInt(placement.WindowRect.Max.X+10, 0, w-panelWidth)
+	y := util.ClampInt(placement.WindowRect.Min.Y, 0, h-panelHeight)
+	settingsWindow.SetLocation(image.Rect(x, y, x+panelWidth, y+panelHeight))
+	isSettingsChanged = false
+
+	_ = ui.AddWindow(settingsWindow)
+	return nil
+}
+
+// CloseSettings closes the settings panel if it is open, and saves if an option was changed in it.
+// Opening the panel alone doesn't save, so a layout that is only being previewed isn't written
+func CloseSettings() {
+	if settingsWindow == nil {
+		return
+	}
+	settingsWindow.Close()
+	settingsWindow = nil
+	if !isSettingsChanged {
+		return
+	}
+	isSettingsChanged = false
+	err := cfg.Save()
+	if err != nil {
+		fmt.Println("config save:", err)
+	}
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("%02X%02X%02X%02X", c.R, c.G, c.B, c.A)
+}
+
+// parseHexColor reads an RRGGBBAA color, ok is false until all 8 digits are typed
+func parseHexColor(value string) (color.RGBA, bool) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(value) != 8 {
+		return color.RGBA{}, false
+	}
+	val, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(val >> 24), G: uint8(val >> 16), B: uint8(val >> 8), A: uint8(val)}, true
+}
@@ -0,0 +1,152 @@
+package placement
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/xackery/critsprinkler/common"
+)
+
+var (
+	// guidesX and guidesY are the guide lines a placement being dragged currently snaps to
+	guidesX []int
+	guidesY []int
+)
+
+// snapTargets returns the screen center and the edges of every other open placement
+func snapTargets(skip *common.Placement) ([]int, []int) {
+	w, h := ebiten.WindowSize()
+	xs := []int{w / 2}
+	ys := []int{h / 2}
+	for _, placement := range placements {
+		if placement == skip || placement.Window == nil {
+			continue
+		}
+		rect := *placement.WindowRect
+		xs = append(xs, rect.Min.X, rect.Max.X)
+		ys = append(ys, rect.Min.Y, rect.Max.Y)
+	}
+	return xs, ys
+}
+
+// snapEdges returns how far to shift so one of the edges lands on a target, and the target hit.
+// If no target is close enough, the first edge is rounded to the grid instead
+func snapEdges(edges []int, targets []int, grid int, distance int) (int, []int) {
+	bestShift := 0
+	var bestTarget []int
+	bestDistance := distance + 1
+	for _, edge := range edges {
+		for _, target := range targets {
+			shift := target - edge
+			d := shift
+			if d < 0 {
+				d = -d
+			}
+			if d < bestDistance {
+				bestShift = shift
+				bestTarget = []int{target}
+				bestDistance = d
+			}
+		}
+	}
+	if bestTarget != nil {
+		return bestShift, bestTarget
+	}
+	if grid <= 0 || len(edges) == 0 {
+		return 0, nil
+	}
+	return roundTo(edges[0], grid) - edges[0], nil
+}
+
+func roundTo(value int, grid int) int {
+	if value < 0 {
+		return -roundTo(-value, grid)
+	}
+	return (value + grid/2) / grid * grid
+}
+
+// snapMove returns rect moved so its edges or center line up with guides or the grid
+func snapMove(placement *common.Placement, rect image.Rectangle) (image.Rectangle, []int, []int) {
+	xs, ys := snapTargets(placement)
+	grid, distance := cfg.PlacementSnapGrid, cfg.PlacementSnapDistance
+
+	center := rect.Min.Add(rect.Max).Div(2)
+	dx, tx := snapEdges([]int{rect.Min.X, center.X, rect.Max.X}, xs, grid, distance)
+	dy, ty := snapEdges([]int{rect.Min.Y, center.Y, rect.Max.Y}, ys, grid, distance)
+	return rect.Add(image.Point{dx, dy}), tx, ty
+}
+
+// snapResize returns rect with its bottom right corner lined up with guides or the grid,
+// never smaller than the category's minimum size
+func snapResize(placement *common.Placement, rect image.Rectangle) (image.Rectangle, []int, []int) {
+	xs, ys := snapTargets(placement)
+	grid, distance := cfg.PlacementSnapGrid, cfg.PlacementSnapDistance
+
+	dx, tx := snapEdges([]int{rect.Max.X}, xs, grid, distance)
+	dy, ty := snapEdges([]int{rect.Max.Y}, ys, grid, distance)
+	rect.Max.X += dx
+	rect.Max.Y += dy
+
+	minWidth, minHeight := placement.Category.MinSize()
+	if rect.Dx() < minWidth {
+		rect.Max.X = rect.Min.X + minWidth
+		tx = nil
+	}
+	if rect.Dy() < minHeight {
+		rect.Max.Y = rect.Min.Y + minHeight
+		ty = nil
+	}
+	return rect, tx, ty
+}
+
+// snap lines a placement up after it was dragged or resized
+func snap(placement *common.Placement) {
+	if placement.Window == nil {
+		return
+	}
+	rect := placement.Window.GetContainer().GetWidget().Rect
+	if rect.Size() == placement.WindowRect.Size() {
+		rect, _, _ = snapMove(placement, rect)
+	} else {
+		rect, _, _ = snapResize(placement, rect)
+	}
+	placement.Window.SetLocation(rect)
+	guidesX, guidesY = nil, nil
+}
+
+// updateGuides finds the guides a placement being dragged would snap to, so they can be drawn
+func updateGuides() {
+	guidesX, guidesY = nil, nil
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	for _, placement := range placements {
+		if placement.Window == nil {
+			continue
+		}
+		rect := placement.Window.GetContainer().GetWidget().Rect
+		if rect == *placement.WindowRect {
+			continue
+		}
+		if rect.Size() == placement.WindowRect.Size() {
+			_, guidesX, guidesY = snapMove(placement, rect)
+			return
+		}
+		_, guidesX, guidesY = snapResize(placement, rect)
+		return
+	}
+}
+
+// Draw draws alignment guides while a placement is being dragged
+func Draw(screen *ebiten.Image) {
+	w, h := ebiten.WindowSize()
+	guideColor := color.RGBA{0, 200, 255, 200}
+	for _, x := range guidesX {
+		vector.StrokeLine(screen, float32(x), 0, float32(x), float32(h), 1, guideColor, false)
+	}
+	for _, y := range guidesY {
+		vector.StrokeLine(screen, 0, float32(y), float32(w), float32(y), 1, guideColor, false)
+	}
+}
@@ -0,0 +1,152 @@
+package popup
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/xackery/critsprinkler/common"
+)
+
+const (
+	// spawnAttempts is how many candidate positions are tried before settling for the least overlap
+	spawnAttempts = 12
+)
+
+// popupRect is the area a popup covers, relative to its placement
+type popupRect struct {
+	minX, minY float64
+	maxX, maxY float64
+}
+
+func (r popupRect) overlap(o popupRect) float64 {
+	w := math.Min(r.maxX, o.maxX) - math.Max(r.minX, o.minX)
+	h := math.Min(r.maxY, o.maxY) - math.Max(r.minY, o.minY)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+func (p *Popup) rect() popupRect {
+	return popupRect{
+		minX: p.x,
+		minY: p.y,
+		maxX: p.x + p.width,
+		maxY: p.y + p.height,
+	}
+}
+
+// placementPopups returns live popups that belong to a category, oldest first
+func placementPopups(category common.PopupCategory) []*Popup {
+	out := []*Popup{}
+	for _, popup := range popups {
+		if popup.category != category {
+			continue
+		}
+		out = append(out, popup)
+	}
+	return out
+}
+
+// spawnPosition finds a spot inside a placement where a popup of the given size
+// does not overlap other live popups of the same placement.
+// If no free spot is found, the candidate with the least overlap is used
+func spawnPosition(setting *common.Placement, width, height float64) (float64, float64) {
+	others := placementPopups(setting.Category)
+
+	maxX := setting.WindowRect.Dx() - int(width)
+	maxY := setting.WindowRect.Dy() - int(height)
+
+	bestX, bestY := 0.0, 0.0
+	bestOverlap := math.MaxFloat64
+	for attempt := 0; attempt < spawnAttempts; attempt++ {
+		x := randomSpawnRange(setting.LastSpawnX, 0, maxX, setting.WindowRect.Dx()/4, spawnAttempts)
+		y := randomSpawnRange(setting.LastSpawnY, 0, maxY, setting.WindowRect.Dy()/4, spawnAttempts)
+		candidate := popupRect{minX: x, minY: y, maxX: x + width, maxY: y + height}
+
+		overlap := 0.0
+		for _, other := range others {
+			overlap += candidate.overlap(other.rect())
+		}
+		if overlap < bestOverlap {
+			bestX, bestY = x, y
+			bestOverlap = overlap
+		}
+		if overlap == 0 {
+			break
+		}
+	}
+
+	setting.LastSpawnX = int(bestX)
+	setting.LastSpawnY = int(bestY)
+	return bestX, bestY
+}
+
+// makeRoom enforces the popup cap of a placement before a new popup is spawned.
+// It returns true if the event was merged into an existing popup and should not spawn
+func makeRoom(setting *common.Placement, event *common.DamageEvent) bool {
+	maxPopups := cfg.PopupMaxPerPlacement
+	if maxPopups <= 0 {
+		return false
+	}
+
+	live := placementPopups(setting.Category)
+	if len(live) < maxPopups {
+		return false
+	}
+
+	if cfg.PopupIsOverflowMerged && mergeOverflow(live, event) {
+		return true
+	}
+
+	for len(live) >= maxPopups {
+		remove(live[0])
+		live = live[1:]
+	}
+	return false
+}
+
+// mergeOverflow adds an event to the oldest live popup with the same source, target and verb,
+// so a merged total is always damage one attacker dealt to one target. It returns true if the event was merged
+func mergeOverflow(live []*Popup, event *common.DamageEvent) bool {
+	val, err := strconv.Atoi(event.Damage)
+	if err != nil {
+		return false
+	}
+	for _, popup := range live {
+		// tallies already sum by their own key, which is empty for a tally of the whole category
+		if popup.isTallyEnabled || popup.targetDamage <= 0 {
+			continue
+		}
+		if popup.source != event.Source || popup.target != event.Target || popup.verb != event.Type {
+			continue
+		}
+		popup.targetDamage += val
+		popup.hits++
+		popup.life = popup.maxLife
+		popup.text = popup.damageText()
+		popup.width, popup.height = measure(popup.text, *popup.face)
+		return true
+	}
+	return false
+}
+
+// remove takes a popup off the screen
+func remove(target *Popup) {
+	for i, popup := range popups {
+		if popup != target {
+			continue
+		}
+		popups = append(popups[:i], popups[i+1:]...)
+		return
+	}
+}
+
+// measure returns the size of a popup's text
+func measure(msg string, face text.Face) (float64, float64) {
+	if face == nil {
+		return 0, 0
+	}
+	return text.Measure(msg, face, 0)
+}
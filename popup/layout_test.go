@@ -0,0 +1,38 @@
+package popup
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/xackery/critsprinkler/common"
+	"github.com/xackery/critsprinkler/config"
+)
+
+func TestMergeOverflow(t *testing.T) {
+	cfg = &config.CritSprinklerConfiguration{}
+	defer func() { cfg = nil }()
+
+	var face text.Face
+	// a tally of the whole category has an empty key
+	tally := &Popup{isTallyEnabled: true, currentDamage: 100, targetDamage: 100, hits: 3, source: "Xackery", target: "a rat", verb: "slash", face: &face}
+	hit := &Popup{currentDamage: 10, targetDamage: 10, hits: 1, source: "Xackery", target: "a rat", verb: "slash", face: &face}
+	other := &Popup{currentDamage: 5, targetDamage: 5, hits: 1, source: "Xackery", target: "a snake", verb: "slash", face: &face}
+	event := &common.DamageEvent{Source: "Xackery", Target: "a rat", Type: "slash", Damage: "7"}
+
+	if !mergeOverflow([]*Popup{tally, other, hit}, event) {
+		t.Fatalf("expected the hit to be merged")
+	}
+	if hit.targetDamage != 17 || hit.hits != 2 {
+		t.Errorf("hit: got %d damage over %d hits, want 17 over 2", hit.targetDamage, hit.hits)
+	}
+	if tally.targetDamage != 100 || tally.hits != 3 {
+		t.Errorf("tally: got %d damage over %d hits, want it untouched", tally.targetDamage, tally.hits)
+	}
+	if other.targetDamage != 5 {
+		t.Errorf("other target: got %d damage, want it untouched", other.targetDamage)
+	}
+
+	if mergeOverflow([]*Popup{tally, other}, event) {
+		t.Errorf("expected nothing to merge into beside a tally")
+	}
+}
@@ -3,8 +3,8 @@ package popup
 import (
 	"fmt"
 	"image/color"
+	"math"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -14,14 +14,27 @@ import (
 	"github.com/xackery/critsprinkler/config"
 	"github.com/xackery/critsprinkler/placement"
 	"github.com/xackery/critsprinkler/tracker"
+	"github.com/xackery/critsprinkler/util"
 	"golang.org/x/exp/rand"
 )
 
+const (
+	// referenceTick is the tick length the animation speeds were originally tuned for
+	referenceTick = time.Second / 60
+	// maxFrameDelta caps how far animations advance in one update, e.g. after the window was dragged
+	maxFrameDelta = 250 * time.Millisecond
+	// tallyLinger is how much longer than a normal popup a tally stays on screen
+	tallyLinger = 1000 * referenceTick
+	// tallyExtend is added to a tally's life every time it absorbs a hit
+	tallyExtend = 10 * referenceTick
+)
+
+// popups are only touched from the ebiten game loop, see tracker.Update
 var (
-	mu             sync.RWMutex
-	popups         []*Popup
-	tallyDuration  *time.Duration
-	isCommaEnabled *bool
+	cfg           *config.CritSprinklerConfiguration
+	popups        []*Popup
+	tallyDuration *time.Duration
+	lastUpdate    time.Time
 )
 
 type Popup struct {
@@ -34,8 +47,9 @@ type Popup struct {
 	face           *text.Face
 	startX, startY float64
 	x, y           float64
+	width, height  float64
 	vx, vy         float64
-	life           float64
+	life           float64 // seconds left on screen
 	maxLife        float64
 	color          color.RGBA
 	isWave         bool
@@ -43,17 +57,23 @@ type Popup struct {
 	waveMin        float64
 	isSmall        bool
 	tallyEndTime   time.Time
+	source         string
+	target         string
+	verb           string
+	hits           int
+	flurryEndTime  time.Time
+	tallyKey       string
+	label          string
 }
 
-func New(cfg *config.CritSprinklerConfiguration) error {
-	mu.Lock()
-	defer mu.Unlock()
-	isCommaEnabled = &cfg.IsCommaEnabled
+func New(ecfg *config.CritSprinklerConfiguration) error {
+	cfg = ecfg
 	tallyDuration = &cfg.PopupTallyDuration
 
 	return nil
 }
 
+// randomSpawnRange returns a popup position between minPos and maxPos, trying to keep it tolerance away from the last one
 func randomSpawnRange(lastSpawnX, minPos, maxPos, tolerance, maxAttempts int) float64 {
 	if minPos == 0 && maxPos == 0 {
 		return 0
@@ -87,24 +107,26 @@ func Update() {
 		}
 	}
 
+	dt := util.Elapsed(&lastUpdate, maxFrameDelta)
+	// count up tallies by half the remaining distance every reference tick
+	countUp := 1 - math.Pow(0.5, dt/referenceTick.Seconds())
+
 	for i := len(popups) - 1; i >= 0; i-- {
 		popup := popups[i]
 
-		popup.y += popup.vy * 0.9
-		popup.x += popup.vx
-		popup.life -= 1
+		popup.y += popup.vy * 0.9 * dt
+		popup.x += popup.vx * dt
+		popup.life -= dt
 
 		if popup.currentDamage < popup.targetDamage {
-			delta := int((float64(popup.targetDamage-popup.currentDamage) * 0.5))
+			delta := int((float64(popup.targetDamage-popup.currentDamage) * countUp))
 			if delta < 1 {
 				delta = 1
 			}
 			popup.currentDamage += delta
 
-			popup.text = strconv.Itoa(popup.currentDamage)
-			if *isCommaEnabled && popup.currentDamage > 0 {
-				popup.text = commaFormat(popup.currentDamage)
-			}
+			popup.text = popup.damageText()
+			popup.width, popup.height = measure(popup.text, *popup.face)
 		}
 
 		// Reverse velocity after reaching the hover point
@@ -158,8 +180,6 @@ func spawn(event *common.DamageEvent) error {
 	spellColor.B = setting.FontColor.B
 	spellColor.A = setting.FontColor.A
 
-	spellColor = color.RGBA{100, 100, 255, 255}
-
 	/*
 		spellColor, ok := spellColors[event.SpellName]
 		if !ok {
@@ -204,13 +224,21 @@ func spawn(event *common.DamageEvent) error {
 		return nil
 	}
 
+	if setting.IsFiltered(event, tracker.PlayerName()) {
+		return spawnTotal(event)
+	}
+
+	tallyKey := setting.TallyKey.Key(event)
 	if setting.IsTallyEnabled == 1 {
 		for i := 0; i < len(popups); i++ {
 			popup := popups[i]
 			if time.Now().After(popup.tallyEndTime) {
 				continue
 			}
-			if popup.category != event.Category {
+			if !popup.isTallyEnabled || popup.category != event.Category {
+				continue
+			}
+			if popup.tallyKey != tallyKey {
 				continue
 			}
 
@@ -220,42 +248,24 @@ func spawn(event *common.DamageEvent) error {
 			}
 
 			popup.targetDamage += val
-			popup.x -= popup.vx
-			popup.y -= popup.vy
-			popup.life += 10
+			popup.hits++
+			popup.text = popup.damageText()
+			popup.width, popup.height = measure(popup.text, *popup.face)
+			popup.x -= popup.vx * referenceTick.Seconds()
+			popup.y -= popup.vy * referenceTick.Seconds()
+			popup.life += tallyExtend.Seconds()
 			return spawnTotal(event)
 		}
 	}
 
-	vx := float64(0)
-	vy := float64(-0.5 - rand.Float64() - (rand.Float64() / 2))
-	switch setting.Direction {
-	case common.DirectionUp:
-		vy = -0.5 - rand.Float64() - (rand.Float64() / 2)
-		vx = 0
-	case common.DirectionDown:
-		vy = 0.5 + rand.Float64() + (rand.Float64() / 2)
-		vx = 0
-	case common.DirectionLeft:
-		vy = 0
-		vx = -0.5 - rand.Float64() - (rand.Float64() / 2)
-	case common.DirectionRight:
-		vy = 0
-		vx = 0.5 + rand.Float64() + (rand.Float64() / 2)
-	case common.DirectionUpLeft:
-		vy = -0.5 - rand.Float64() - (rand.Float64() / 2)
-		vx = -0.5 - rand.Float64() - (rand.Float64() / 2)
-	case common.DirectionUpRight:
-		vy = -0.5 - rand.Float64() - (rand.Float64() / 2)
-		vx = 0.5 + rand.Float64() + (rand.Float64() / 2)
-	case common.DirectionDownLeft:
-		vy = 0.5 + rand.Float64() + (rand.Float64() / 2)
-		vx = -0.5 - rand.Float64() - (rand.Float64() / 2)
-	case common.DirectionDownRight:
-		vy = 0.5 + rand.Float64() + (rand.Float64() / 2)
-		vx = 0.5 + rand.Float64() + (rand.Float64() / 2)
+	if setting.IsFlurryMerged && mergeFlurry(setting, event) {
+		return spawnTotal(event)
 	}
 
+	dx, dy := setting.Direction.Vector()
+	vx := dx * util.RandomSpeed()
+	vy := dy * util.RandomSpeed()
+
 	fmt.Printf("%s->%s->%s (%s) %s %s\n", event.Source, event.Type, event.Target, event.SpellName, event.Damage, event.Category.String())
 
 	val, err := strconv.Atoi(event.Damage)
@@ -263,10 +273,17 @@ func spawn(event *common.DamageEvent) error {
 		val = 0
 	}
 	damage := event.Damage
-	if *isCommaEnabled && val > 0 {
-		damage = commaFormat(val)
+	if val > 0 {
+		damage = cfg.NumberFormat().Format(val)
+	}
+
+	if makeRoom(setting, event) {
+		return spawnTotal(event)
 	}
 
+	width, height := measure(damage, setting.FontFace)
+	x, y := spawnPosition(setting, width, height)
+
 	popup := &Popup{
 		category:      event.Category,
 		text:          damage,
@@ -275,53 +292,49 @@ func spawn(event *common.DamageEvent) error {
 		baseX:         &setting.WindowRect.Min.X,
 		baseY:         &setting.WindowRect.Min.Y,
 		face:          &setting.FontFace,
-		x:             randomSpawnRange(int(setting.LastSpawnX), 0, setting.WindowRect.Dx()-50, setting.WindowRect.Dx()/4, 0),
-		y:             randomSpawnRange(int(setting.LastSpawnX), 0, setting.WindowRect.Dy()-50, setting.WindowRect.Dy()/4, 0),
+		x:             x,
+		y:             y,
+		width:         width,
+		height:        height,
 		vx:            vx,
 		vy:            vy,
-		life:          240,
-		maxLife:       240,
+		life:          setting.Duration.Seconds(),
+		maxLife:       setting.Duration.Seconds(),
 		tallyEndTime:  time.Now().Add(*tallyDuration),
 		color:         spellColor,
+		source:        event.Source,
+		target:        event.Target,
+		verb:          event.Type,
+		hits:          1,
+		flurryEndTime: time.Now().Add(setting.FlurryWindow),
 	}
 	if setting.IsTallyEnabled == 1 {
-		popup.maxLife += 1000
-		popup.life += 1000
-		switch setting.Direction {
-		case common.DirectionUp:
-			popup.vy = -0.2
-		case common.DirectionDown:
-			popup.vy = 0.2
-		case common.DirectionLeft:
-			popup.vx = -0.4
-		case common.DirectionRight:
-			popup.vx = 0.4
-		case common.DirectionUpLeft:
-			popup.vx = -0.2
-			popup.vy = -0.2
-		case common.DirectionUpRight:
-			popup.vx = 0.2
-			popup.vy = -0.2
-		case common.DirectionDownLeft:
-			popup.vx = -0.2
-			popup.vy = 0.2
-		case common.DirectionDownRight:
-			popup.vx = 0.2
-			popup.vy = 0.2
+		popup.isTallyEnabled = true
+		popup.tallyKey = tallyKey
+		if setting.IsTallyLabeled {
+			popup.label = setting.TallyKey.Label(event)
+		}
+		if val > 0 {
+			popup.text = popup.damageText()
+			popup.width, popup.height = measure(popup.text, *popup.face)
+		}
+		popup.maxLife += tallyLinger.Seconds()
+		popup.life += tallyLinger.Seconds()
+		tallySpeed := 12.0
+		if dy == 0 {
+			tallySpeed = 24
+		}
+		popup.vx = dx * tallySpeed
+		popup.vy = dy * tallySpeed
+		// a single tally sits in the middle, keyed tallies spread out so they don't stack
+		if tallyKey == "" {
+			popup.x = float64(setting.WindowRect.Dx() / 2)
+			popup.y = float64(setting.WindowRect.Dy() / 2)
 		}
-		popup.x = float64(setting.WindowRect.Dx() / 2)
-		popup.y = float64(setting.WindowRect.Dy() / 2)
 	}
 	popup.startX = popup.x
 	popup.startY = popup.y
-	if popup.isSmall || event.Origin == "melee" {
-		//popup.y += 200
-		popup.life = 250
-		popup.maxLife = 500
-	}
 	if event.Origin == "dot" {
-		popup.life = 240
-		popup.maxLife = 240
 		popup.isWave = true
 		popup.waveMax = popup.y + 10
 		popup.waveMin = popup.y - 10
@@ -331,10 +344,54 @@ func spawn(event *common.DamageEvent) error {
 	return spawnTotal(event)
 }
 
-// ConfigUpdate updates the popup configuration
-func ConfigUpdate(cfg *config.CritSprinklerConfiguration) {
-	mu.Lock()
-	defer mu.Unlock()
+// mergeFlurry folds a hit into a live popup with the same source, target and verb.
+// It returns true if the event was merged
+func mergeFlurry(setting *common.Placement, event *common.DamageEvent) bool {
+	val, err := strconv.Atoi(event.Damage)
+	if err != nil {
+		return false
+	}
+	now := time.Now()
+	for _, popup := range placementPopups(setting.Category) {
+		if now.After(popup.flurryEndTime) {
+			continue
+		}
+		if popup.source != event.Source || popup.target != event.Target || popup.verb != event.Type {
+			continue
+		}
+		popup.targetDamage += val
+		popup.hits++
+		popup.life = popup.maxLife
+		popup.flurryEndTime = now.Add(setting.FlurryWindow)
+		popup.text = popup.damageText()
+		popup.width, popup.height = measure(popup.text, *popup.face)
+		return true
+	}
+	return false
+}
+
+// damageText returns the text shown for a popup's current damage, e.g. 1,842 x4 or Ice Comet: 12,345 x3
+func (p *Popup) damageText() string {
+	msg := cfg.NumberFormat().Format(p.currentDamage)
+	if p.hits > 1 {
+		msg += fmt.Sprintf(" x%d", p.hits)
+	}
+	if p.label != "" {
+		msg = p.label + ": " + msg
+	}
+	return msg
+}
+
+// ConfigUpdate applies reloaded popup options, and drops popups of placements that were closed
+func ConfigUpdate(ecfg *config.CritSprinklerConfiguration) {
+	cfg = ecfg
+	tallyDuration = &cfg.PopupTallyDuration
+	for _, p := range append([]*Popup{}, popups...) {
+		setting := placement.ByCategory(p.category)
+		if setting == nil || setting.IsVisible == 0 {
+			remove(p)
+		}
+	}
 }
 
 func spawnTotal(event *common.DamageEvent) error {
@@ -358,38 +415,32 @@ func spawnTotal(event *common.DamageEvent) error {
 	return nil
 }
 
-func commaFormat(num int) string {
-	if num < 1000 {
-		return strconv.Itoa(num)
-	}
-	in := strconv.Itoa(num)
-	n := len(in) % 3
-	out := in[:n]
-	for i := n; i < len(in); i += 3 {
-		if len(out) > 0 {
-			out += ","
-		}
-		out += in[i : i+3]
-	}
-	return out
-}
-
 func (p *Popup) Clone() *Popup {
 	return &Popup{
-		text:          p.text,
-		currentDamage: p.currentDamage,
-		targetDamage:  p.targetDamage,
-		x:             p.x,
-		y:             p.y,
-		vx:            p.vx,
-		vy:            p.vy,
-		life:          p.life,
-		maxLife:       p.maxLife,
-		color:         p.color,
-		isWave:        p.isWave,
-		waveMax:       p.waveMax,
-		waveMin:       p.waveMin,
-		isSmall:       p.isSmall,
-		tallyEndTime:  p.tallyEndTime,
+		text:           p.text,
+		isTallyEnabled: p.isTallyEnabled,
+		currentDamage:  p.currentDamage,
+		targetDamage:   p.targetDamage,
+		x:              p.x,
+		y:              p.y,
+		width:          p.width,
+		height:         p.height,
+		vx:             p.vx,
+		vy:             p.vy,
+		life:           p.life,
+		maxLife:        p.maxLife,
+		color:          p.color,
+		isWave:         p.isWave,
+		waveMax:        p.waveMax,
+		waveMin:        p.waveMin,
+		isSmall:        p.isSmall,
+		tallyEndTime:   p.tallyEndTime,
+		source:         p.source,
+		target:         p.target,
+		verb:           p.verb,
+		hits:           p.hits,
+		flurryEndTime:  p.flurryEndTime,
+		tallyKey:       p.tallyKey,
+		label:          p.label,
 	}
 }
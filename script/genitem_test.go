@@ -0,0 +1,105 @@
+package script
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenItem(t *testing.T) {
+	path := "c:/games/eq/thj/items.txt"
+	_, err := os.Stat(path)
+	if err != nil {
+		t.Skipf("no items export at %s", path)
+	}
+	err = ItemIcons(path, "../library/item_icon_table.go")
+	if err != nil {
+		t.Fatalf("ItemIcons: %v", err)
+	}
+}
+
+// ItemIcons writes the icon of every item in an items export of the server database, a ^ or | separated
+// file with a header row naming its columns, e.g. the items table of an EQEmu database
+func ItemIcons(path string, outputPath string) error {
+	start := time.Now()
+	defer func() {
+		fmt.Printf("Finished in %0.2f seconds\n", time.Since(start).Seconds())
+	}()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r", ""), "\n")
+	separator := "^"
+	if !strings.Contains(lines[0], separator) {
+		separator = "|"
+	}
+	nameColumn := -1
+	iconColumn := -1
+	for i, column := range strings.Split(lines[0], separator) {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "name":
+			nameColumn = i
+		case "icon":
+			iconColumn = i
+		}
+	}
+	if nameColumn < 0 || iconColumn < 0 {
+		return fmt.Errorf("header has no name and icon column")
+	}
+
+	icons := map[string]int{}
+	for i, line := range lines[1:] {
+		records := strings.Split(line, separator)
+		if len(records) <= nameColumn || len(records) <= iconColumn {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(records[nameColumn]))
+		icon, err := strconv.Atoi(strings.TrimSpace(records[iconColumn]))
+		if err != nil {
+			return fmt.Errorf("line %d: %s icon %q: %w", i+2, name, records[iconColumn], err)
+		}
+		// items that share a name keep the first icon, like the spell colors
+		_, ok := icons[name]
+		if name == "" || icon <= 0 || ok {
+			continue
+		}
+		icons[name] = icon
+	}
+
+	names := []string{}
+	for name := range icons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := &strings.Builder{}
+	out.WriteString(`package library
+
+// itemIconTable is the icon id of every item by lowercase name, generated by script/genitem_test.go
+var itemIconTable = map[string]int{`)
+	for _, name := range names {
+		out.WriteString(fmt.Sprintf("\n%q: %d,", name, icons[name]))
+	}
+	out.WriteString("\n}\n")
+
+	src, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return fmt.Errorf("format: %w", err)
+	}
+	err = os.WriteFile(outputPath, src, 0644)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", outputPath, err)
+	}
+
+	fmt.Printf("%d items written to %s\n", len(names), outputPath)
+
+	return nil
+}
@@ -29,9 +29,10 @@ const (
 )
 
 var (
-	cfg     *config.CritSprinklerConfiguration
-	sounds  = make(map[SoundEffect][]byte)
-	context = audio.NewContext(44100)
+	cfg          *config.CritSprinklerConfiguration
+	loadedEQPath string // EQPath the eq sounds were last loaded from
+	sounds       = make(map[SoundEffect][]byte)
+	context      = audio.NewContext(44100)
 )
 
 func (e SoundEffect) String() string {
@@ -113,6 +114,7 @@ func OnEQPathLoad() {
 	if cfg.EQPath == "" {
 		return
 	}
+	loadedEQPath = cfg.EQPath
 
 	elements := []struct {
 		isArchiveFile   bool
@@ -196,3 +198,11 @@ func loadEQSound(archivePath, fileName string, key SoundEffect) error {
 	sounds[key] = buf.Bytes()
 	return nil
 }
+
+// ConfigUpdate reloads eq sounds if the config now points at another eq path
+func ConfigUpdate() {
+	if cfg == nil || cfg.EQPath == loadedEQPath {
+		return
+	}
+	OnEQPathLoad()
+}
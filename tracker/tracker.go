@@ -10,12 +10,29 @@ import (
 	"github.com/hpcloud/tail"
 )
 
+const (
+	// maxLinesPerUpdate limits how many queued lines are dispatched in one game tick
+	maxLinesPerUpdate = 1000
+)
+
 var (
 	instance  *Tracker
 	timeRegex = regexp.MustCompile(`\[(.*?)\]`)
 	zoneRegex = regexp.MustCompile(`You have entered (.*)`)
 )
 
+// lineEvent is a parsed log line waiting to be dispatched on the game loop
+type lineEvent struct {
+	event time.Time
+	line  string
+}
+
+// Tracker tails an EQ log file.
+//
+// The tail runs on its own goroutine and only queues lines into a mailbox.
+// Subscribers are called from Update, which runs on the ebiten game loop,
+// so every subscriber owns its state without locks as long as it is only
+// touched from the game loop too.
 type Tracker struct {
 	path          string
 	onLineEvent   []func(time.Time, string)
@@ -26,6 +43,8 @@ type Tracker struct {
 	name          string
 	pollCtx       context.Context
 	pollCtxCancel context.CancelFunc
+	tailer        *tail.Tail
+	lines         chan lineEvent
 }
 
 func New(path string) (*Tracker, error) {
@@ -36,6 +55,7 @@ func New(path string) (*Tracker, error) {
 	t := &Tracker{
 		path:         path,
 		trackerStart: time.Now(),
+		lines:        make(chan lineEvent, 10000),
 	}
 	instance = t
 
@@ -110,9 +130,7 @@ func (t *Tracker) watchFile() error {
 		return nil
 	}
 
-	if t.pollCtx != nil {
-		t.pollCtxCancel()
-	}
+	t.stopTail()
 
 	config := tail.Config{
 		Follow:    true,
@@ -131,37 +149,99 @@ func (t *Tracker) watchFile() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	t.pollCtx = ctx
 	t.pollCtxCancel = cancel
-	go t.poll(ctx, tailer)
+	t.tailer = tailer
+	go t.poll(ctx, tailer, t.lines)
+	return nil
+}
+
+// stopTail stops the current tail goroutine, if any
+func (t *Tracker) stopTail() {
+	if t.pollCtx != nil {
+		t.pollCtxCancel()
+		t.pollCtx = nil
+	}
+	if t.tailer != nil {
+		t.tailer.Stop()
+		t.tailer = nil
+	}
+}
+
+// Stop stops tailing the log file
+func (t *Tracker) Stop() error {
+	if !t.isStarted {
+		return fmt.Errorf("tracker not started")
+	}
+	t.stopTail()
+	t.isStarted = false
 	return nil
 }
 
-func (t *Tracker) poll(ctx context.Context, tailer *tail.Tail) {
+// poll runs on its own goroutine. It must not touch tracker state,
+// it only parses timestamps and hands lines to the mailbox.
+// Once cancelled it keeps draining the tailer so tail.Stop can return
+func (t *Tracker) poll(ctx context.Context, tailer *tail.Tail, lines chan<- lineEvent) {
 	for line := range tailer.Lines {
 		select {
 		case <-ctx.Done():
-			return
+			continue
 		default:
 		}
 
-		match := timeRegex.FindStringSubmatch(line.Text)
-		if len(match) < 2 {
-			continue
-		}
-		event, err := time.Parse("Mon Jan 02 15:04:05 2006", match[1])
-		if err != nil {
+		event, ok := parseTime(line.Text, time.Local)
+		if !ok {
 			continue
 		}
 
-		if !t.isLiveParse && event.After(t.trackerStart) {
-			t.isLiveParse = true
+		select {
+		case lines <- lineEvent{event: event, line: line.Text}:
+		case <-ctx.Done():
 		}
-		for _, fn := range t.onLineEvent {
-			fn(event, line.Text)
+	}
+}
+
+// parseTime returns the timestamp a log line starts with. EQ writes it in the local time of the machine,
+// so it is parsed in loc, time.Local outside of tests, and compares with time.Now and file mod times
+func parseTime(line string, loc *time.Location) (time.Time, bool) {
+	match := timeRegex.FindStringSubmatch(line)
+	if len(match) < 2 {
+		return time.Time{}, false
+	}
+	event, err := time.ParseInLocation("Mon Jan 02 15:04:05 2006", match[1], loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return event, true
+}
+
+// Update is called by the game loop to dispatch queued lines to subscribers
+func Update() {
+	if instance == nil {
+		return
+	}
+	instance.dispatch(maxLinesPerUpdate)
+}
+
+func (t *Tracker) dispatch(limit int) {
+	for i := 0; i < limit; i++ {
+		select {
+		case e := <-t.lines:
+			t.onLine(e.event, e.line)
+		default:
+			return
 		}
-		t.onZone(event, line.Text)
 	}
 }
 
+func (t *Tracker) onLine(event time.Time, line string) {
+	if !t.isLiveParse && event.After(t.trackerStart) {
+		t.isLiveParse = true
+	}
+	for _, fn := range t.onLineEvent {
+		fn(event, line)
+	}
+	t.onZone(event, line)
+}
+
 func (t *Tracker) onZone(event time.Time, line string) {
 	match := zoneRegex.FindStringSubmatch(line)
 	if len(match) < 2 {
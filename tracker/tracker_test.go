@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPipeline drives a synthetic log from a writer goroutine while the test
+// goroutine plays the game loop. Run with -race to verify subscribers are only
+// called from Update.
+func TestPipeline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eqlog_Tester_test.txt")
+	err := os.WriteFile(path, nil, 0644)
+	if err != nil {
+		t.Fatalf("create log: %v", err)
+	}
+
+	instance = nil
+	tr, err := New(path)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer func() { instance = nil }()
+
+	// state owned by the "game loop", intentionally unguarded
+	lines := []string{}
+	zones := []string{}
+	err = Subscribe(func(event time.Time, line string) {
+		lines = append(lines, line)
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	err = SubscribeToZoneEvent(func(event time.Time, zone string) {
+		zones = append(zones, zone)
+	})
+	if err != nil {
+		t.Fatalf("subscribe zone: %v", err)
+	}
+
+	err = tr.Start(true)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer tr.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		w, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return
+		}
+		defer w.Close()
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+			stamp := time.Now().Format("Mon Jan 02 15:04:05 2006")
+			if i%10 == 0 {
+				fmt.Fprintf(w, "[%s] You have entered Zone %d.\n", stamp, i)
+				continue
+			}
+			fmt.Fprintf(w, "[%s] You hit a training dummy for %d points of damage.\n", stamp, i)
+		}
+	}()
+
+	deadline := time.Now().Add(15 * time.Second)
+	for len(lines) < 50 || len(zones) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out with %d lines and %d zones", len(lines), len(zones))
+		}
+		Update()
+		_ = PlayerName()
+		_ = IsLiveParse()
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "[") {
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	if PlayerName() != "Tester" {
+		t.Fatalf("player name %q", PlayerName())
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	// 10 hours ahead of UTC, like a player in Australia
+	loc := time.FixedZone("AEST", 10*60*60)
+	event, ok := parseTime("[Fri Jan 02 15:04:05 2026] You have entered Crystal Caverns.", loc)
+	if !ok {
+		t.Fatalf("expected a timestamp")
+	}
+	want := time.Date(2026, 1, 2, 5, 4, 5, 0, time.UTC)
+	if !event.Equal(want) {
+		t.Errorf("got %s, want %s", event.UTC(), want)
+	}
+
+	_, ok = parseTime("no timestamp", loc)
+	if ok {
+		t.Errorf("no timestamp: expected none")
+	}
+}
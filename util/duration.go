@@ -0,0 +1,15 @@
+package util
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatDuration returns a duration as hours and minutes, e.g. 1h05m
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
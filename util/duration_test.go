@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{90 * time.Second, "2m"},
+		{65 * time.Minute, "1h05m"},
+		{26 * time.Hour, "26h00m"},
+	}
+	for _, test := range tests {
+		got := FormatDuration(test.d)
+		if got != test.want {
+			t.Errorf("FormatDuration(%s): got %s, want %s", test.d, got, test.want)
+		}
+	}
+}
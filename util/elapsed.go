@@ -0,0 +1,30 @@
+package util
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Elapsed returns the seconds passed since last, capped to limit, and moves last to now.
+// Animations scale their movement by it so they look the same at any tick or refresh rate
+func Elapsed(last *time.Time, limit time.Duration) float64 {
+	now := time.Now()
+	if last.IsZero() {
+		*last = now
+		return 0
+	}
+	delta := now.Sub(*last)
+	*last = now
+	if delta > limit {
+		delta = limit
+	}
+	if delta < 0 {
+		delta = 0
+	}
+	return delta.Seconds()
+}
+
+// RandomSpeed returns a launch speed in pixels per second for popups and coins, weighted toward the middle
+func RandomSpeed() float64 {
+	return 30 + 60*rand.Float64() + 30*rand.Float64()
+}
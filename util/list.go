@@ -0,0 +1,16 @@
+package util
+
+import "strings"
+
+// SplitList splits a comma separated list, trimming spaces and dropping empty entries
+func SplitList(value string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		list = append(list, entry)
+	}
+	return list
+}
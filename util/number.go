@@ -0,0 +1,168 @@
+package util
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberSeparator is the thousands separator used when formatting numbers
+type NumberSeparator int
+
+const (
+	NumberSeparatorNone NumberSeparator = iota
+	NumberSeparatorComma
+	NumberSeparatorPeriod
+	NumberSeparatorSpace
+)
+
+// String returns the string representation of the NumberSeparator.
+func (s NumberSeparator) String() string {
+	switch s {
+	case NumberSeparatorNone:
+		return "None"
+	case NumberSeparatorComma:
+		return "Comma"
+	case NumberSeparatorPeriod:
+		return "Period"
+	case NumberSeparatorSpace:
+		return "Space"
+	}
+	return "Unknown"
+}
+
+// IsValid returns true for a known separator
+func (s NumberSeparator) IsValid() bool {
+	return s >= NumberSeparatorNone && s <= NumberSeparatorSpace
+}
+
+// NumberAbbreviation controls when large numbers are shortened to 1.2k, 3.45M style
+type NumberAbbreviation int
+
+const (
+	NumberAbbreviationNone NumberAbbreviation = iota
+	NumberAbbreviationThousands
+	NumberAbbreviationMillions
+)
+
+// String returns the string representation of the NumberAbbreviation.
+func (a NumberAbbreviation) String() string {
+	switch a {
+	case NumberAbbreviationNone:
+		return "None"
+	case NumberAbbreviationThousands:
+		return "Thousands"
+	case NumberAbbreviationMillions:
+		return "Millions"
+	}
+	return "Unknown"
+}
+
+// IsValid returns true for a known abbreviation
+func (a NumberAbbreviation) IsValid() bool {
+	return a >= NumberAbbreviationNone && a <= NumberAbbreviationMillions
+}
+
+// MaxSignificantDigits is the most significant digits an abbreviated number can be shown with
+const MaxSignificantDigits = 9
+
+// SignificantDigits is how many digits an abbreviated number keeps, e.g. 3 shows 1.23k
+type SignificantDigits int
+
+// IsValid returns true for 1 to MaxSignificantDigits digits
+func (d SignificantDigits) IsValid() bool {
+	return d >= 1 && d <= MaxSignificantDigits
+}
+
+// NumberFormat describes how numbers are shown on popups, tallies and counters
+type NumberFormat struct {
+	Separator         NumberSeparator
+	Abbreviation      NumberAbbreviation
+	SignificantDigits SignificantDigits
+}
+
+var numberSuffixes = []string{"", "k", "M", "B", "T"}
+
+// Format returns num formatted based on the number format
+func (f NumberFormat) Format(num int) string {
+	if num < 0 {
+		// negating math.MinInt overflows, so the magnitude is taken unsigned
+		return "-" + f.formatUnsigned(uint64(-(num+1))+1)
+	}
+	return f.formatUnsigned(uint64(num))
+}
+
+// formatUnsigned formats the magnitude of a number
+func (f NumberFormat) formatUnsigned(num uint64) string {
+	var minAbbreviate uint64
+	switch f.Abbreviation {
+	case NumberAbbreviationThousands:
+		minAbbreviate = 1000
+	case NumberAbbreviationMillions:
+		minAbbreviate = 1000000
+	}
+	if minAbbreviate == 0 || num < minAbbreviate {
+		return f.group(strconv.FormatUint(num, 10))
+	}
+
+	digits := int(f.SignificantDigits)
+	if !f.SignificantDigits.IsValid() {
+		digits = 3
+	}
+
+	unit := 0
+	value := float64(num)
+	for value >= 1000 && unit < len(numberSuffixes)-1 {
+		value /= 1000
+		unit++
+	}
+
+	// round to significant digits, which may carry into the next unit (999.95k -> 1M)
+	precision := digits - len(strconv.Itoa(int(value)))
+	if precision < 0 {
+		precision = 0
+	}
+	scale := math.Pow(10, float64(precision))
+	value = math.Round(value*scale) / scale
+	if value >= 1000 && unit < len(numberSuffixes)-1 {
+		value /= 1000
+		unit++
+		precision = digits - 1
+	}
+
+	out := strconv.FormatFloat(value, 'f', precision, 64)
+	if strings.Contains(out, ".") {
+		out = strings.TrimRight(out, "0")
+		out = strings.TrimSuffix(out, ".")
+	}
+	if f.Separator == NumberSeparatorPeriod {
+		out = strings.Replace(out, ".", ",", 1)
+	}
+	return out + numberSuffixes[unit]
+}
+
+// group inserts the thousands separator into a string of digits
+func (f NumberFormat) group(in string) string {
+	separator := ""
+	switch f.Separator {
+	case NumberSeparatorComma:
+		separator = ","
+	case NumberSeparatorPeriod:
+		separator = "."
+	case NumberSeparatorSpace:
+		separator = " "
+	}
+	if separator == "" || len(in) <= 3 {
+		return in
+	}
+
+	n := len(in) % 3
+	out := in[:n]
+	for i := n; i < len(in); i += 3 {
+		if len(out) > 0 {
+			out += separator
+		}
+		out += in[i : i+3]
+	}
+	return out
+}
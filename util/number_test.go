@@ -0,0 +1,41 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format NumberFormat
+		in     int
+		want   string
+	}{
+		{"plain", NumberFormat{}, 1234567, "1234567"},
+		{"comma", NumberFormat{Separator: NumberSeparatorComma}, 1234567, "1,234,567"},
+		{"period", NumberFormat{Separator: NumberSeparatorPeriod}, 1234567, "1.234.567"},
+		{"space", NumberFormat{Separator: NumberSeparatorSpace}, 1234567, "1 234 567"},
+		{"small", NumberFormat{Separator: NumberSeparatorComma}, 999, "999"},
+		{"negative", NumberFormat{Separator: NumberSeparatorComma}, -12345, "-12,345"},
+		{"thousands", NumberFormat{Abbreviation: NumberAbbreviationThousands, SignificantDigits: 2}, 1234, "1.2k"},
+		{"millions", NumberFormat{Abbreviation: NumberAbbreviationThousands, SignificantDigits: 3}, 3451234, "3.45M"},
+		{"trim zeros", NumberFormat{Abbreviation: NumberAbbreviationThousands, SignificantDigits: 3}, 2000, "2k"},
+		{"large unit", NumberFormat{Abbreviation: NumberAbbreviationThousands, SignificantDigits: 3}, 123456, "123k"},
+		{"carry", NumberFormat{Abbreviation: NumberAbbreviationThousands, SignificantDigits: 3}, 999950, "1M"},
+		{"millions mode below", NumberFormat{Separator: NumberSeparatorComma, Abbreviation: NumberAbbreviationMillions}, 54321, "54,321"},
+		{"millions mode above", NumberFormat{Separator: NumberSeparatorComma, Abbreviation: NumberAbbreviationMillions, SignificantDigits: 3}, 54321000, "54.3M"},
+		{"period decimal", NumberFormat{Separator: NumberSeparatorPeriod, Abbreviation: NumberAbbreviationThousands, SignificantDigits: 3}, 1234, "1,23k"},
+		{"min int", NumberFormat{}, math.MinInt, "-9223372036854775808"},
+		{"min int abbreviated", NumberFormat{Abbreviation: NumberAbbreviationThousands}, math.MinInt, "-9223372T"},
+		{"default digits", NumberFormat{Abbreviation: NumberAbbreviationThousands}, 1234, "1.23k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.format.Format(tt.in)
+			if got != tt.want {
+				t.Fatalf("Format(%d) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}